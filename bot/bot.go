@@ -0,0 +1,128 @@
+// Package bot plays megamine without a mouse, so strategies can be
+// measured against each other under the game's own rules.
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"megamine/engine"
+	"sort"
+	"time"
+)
+
+type ActionKind int
+
+const (
+	Open ActionKind = iota
+	Flag
+	Chord
+)
+
+type Action struct {
+	Kind ActionKind
+	X, Y int
+}
+
+// Player decides every move from what a person would see of the board.
+type Player interface {
+	Move(v *engine.View) Action
+}
+
+// Players makes every known player. Players that guess draw from seed.
+var Players = map[string]func(seed int64) Player{
+	"random": NewRandom,
+	"solver": NewSolver,
+	"odds":   NewOdds,
+}
+
+func Names() []string {
+	var names []string
+	for name := range Players {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func New(name string, seed int64) (Player, error) {
+	mk := Players[name]
+	if mk == nil {
+		return nil, errors.New(fmt.Sprintf("unknown bot: %s", name))
+	}
+	return mk(seed), nil
+}
+
+// stuckMoves is how many moves in a row may change nothing before a game
+// is given up.
+const stuckMoves = 100
+
+// Apply makes the action on the board and reports whether it changed it.
+func Apply(b *engine.Board, a Action) bool {
+	switch a.Kind {
+	case Flag:
+		return b.Flag(a.X, a.Y)
+	case Chord:
+		effective := b.Clicks.ChordEffective
+		b.Chord(a.X, a.Y)
+		return b.Clicks.ChordEffective != effective
+	}
+	return b.Open(a.X, a.Y)
+}
+
+// Play has the player play the board to the end. Games where the player
+// keeps making moves that change nothing are given up and lost.
+func Play(p Player, b *engine.Board) engine.Stats {
+	start := time.Now()
+	idle := 0
+	for !b.Over() && idle < stuckMoves {
+		if Apply(b, p.Move(b.View())) {
+			idle = 0
+		} else {
+			idle++
+		}
+	}
+	return b.Stats(time.Since(start))
+}
+
+// Summary totals the games of a run.
+type Summary struct {
+	Games, Won int
+	Time       time.Duration
+	// BBBVs is the average 3BV/s over won games.
+	BBBVs float64
+}
+
+func (s Summary) WinRate() float64 {
+	if s.Games == 0 {
+		return 0
+	}
+	return float64(s.Won) / float64(s.Games)
+}
+
+// Run plays n games of the difficulty with a fresh player each, dealt
+// from seed, seed+1 and so on. The same arguments play the same games.
+func Run(name string, n int, d engine.Difficulty, rules engine.Rules, seed int64) (Summary, error) {
+	var sum Summary
+	for i := 0; i < n; i++ {
+		p, err := New(name, seed+int64(i))
+		if err != nil {
+			return sum, err
+		}
+		b, err := engine.NewBoard(d.X, d.Y, d.Mines, seed+int64(i))
+		if err != nil {
+			return sum, err
+		}
+		b.Rules = rules
+		st := Play(p, b)
+		sum.Games++
+		sum.Time += st.Time
+		if st.Won {
+			sum.Won++
+			sum.BBBVs += st.BBBVPerSecond()
+		}
+	}
+	if sum.Won > 0 {
+		sum.BBBVs /= float64(sum.Won)
+	}
+	return sum, nil
+}
@@ -0,0 +1,45 @@
+package bot
+
+import (
+	"megamine/engine"
+	"testing"
+)
+
+func TestRunDeterministic(t *testing.T) {
+	rules := engine.DefaultRules()
+	for _, name := range Names() {
+		a, err := Run(name, 30, engine.Beginner, rules, 7)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := Run(name, 30, engine.Beginner, rules, 7)
+		if a.Games != 30 || a.Won != b.Won {
+			t.Errorf("%s: %d/%d won, then %d/%d", name, a.Won, a.Games, b.Won, b.Games)
+		}
+	}
+	if _, err := Run("lucky", 1, engine.Beginner, rules, 1); err == nil {
+		t.Error("unknown bot ran")
+	}
+}
+
+func TestSolverWinsNoGuess(t *testing.T) {
+	solvable := 0
+	for seed := int64(1); seed <= 20; seed++ {
+		b, err := engine.NewBoard(16, 16, 40, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.Rules.NoGuess = true
+		st := Play(NewSolver(seed), b)
+		if !b.Solvable {
+			continue
+		}
+		solvable++
+		if !st.Won {
+			t.Errorf("seed %d: solver lost a board that needs no guess", seed)
+		}
+	}
+	if solvable == 0 {
+		t.Error("no board that needs no guess was found")
+	}
+}
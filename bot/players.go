@@ -0,0 +1,106 @@
+package bot
+
+import (
+	"image"
+	"math/rand"
+	"megamine/engine"
+)
+
+func hidden(v *engine.View) []image.Point {
+	var ps []image.Point
+	for y := 0; y < v.Y; y++ {
+		for x := 0; x < v.X; x++ {
+			if v.Cells[y][x] == engine.ViewHidden {
+				ps = append(ps, image.Point{x, y})
+			}
+		}
+	}
+	return ps
+}
+
+// started reports whether any cell of v is open.
+func started(v *engine.View) bool {
+	for y := 0; y < v.Y; y++ {
+		for x := 0; x < v.X; x++ {
+			if v.Opened(x, y) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Random opens hidden cells at random.
+type Random struct {
+	rand *rand.Rand
+}
+
+func NewRandom(seed int64) Player {
+	return &Random{rand: rand.New(rand.NewSource(seed))}
+}
+
+func (r *Random) Move(v *engine.View) Action {
+	ps := hidden(v)
+	p := ps[r.rand.Intn(len(ps))]
+	return Action{Kind: Open, X: p.X, Y: p.Y}
+}
+
+// Solver opens the middle first, then whatever the numbers prove safe.
+// When nothing is proven it guesses at random among the cells not proven
+// to be mines. It never flags, so it plays the same without flags.
+type Solver struct {
+	Random
+	// odds makes guesses go to the cell least likely to be a mine.
+	odds bool
+}
+
+func NewSolver(seed int64) Player {
+	return &Solver{Random: Random{rand: rand.New(rand.NewSource(seed))}}
+}
+
+// NewOdds is a solver that guesses the safest cell.
+func NewOdds(seed int64) Player {
+	return &Solver{Random: Random{rand: rand.New(rand.NewSource(seed))}, odds: true}
+}
+
+func (s *Solver) Move(v *engine.View) Action {
+	if !started(v) {
+		return Action{Kind: Open, X: v.X / 2, Y: v.Y / 2}
+	}
+	mines := map[image.Point]bool{}
+	for _, d := range engine.Deduce(v) {
+		if !d.Mine {
+			return Action{Kind: Open, X: d.X, Y: d.Y}
+		}
+		mines[image.Point{d.X, d.Y}] = true
+	}
+	if s.odds {
+		if a, ok := s.safest(v); ok {
+			return a
+		}
+	}
+	var ps []image.Point
+	for _, p := range hidden(v) {
+		if !mines[p] {
+			ps = append(ps, p)
+		}
+	}
+	p := ps[s.rand.Intn(len(ps))]
+	return Action{Kind: Open, X: p.X, Y: p.Y}
+}
+
+func (s *Solver) safest(v *engine.View) (Action, bool) {
+	odds, err := engine.Probabilities(v)
+	if err != nil {
+		return Action{}, false
+	}
+	best, bp := image.Point{-1, -1}, 2.0
+	for y, row := range odds {
+		for x, p := range row {
+			if p >= 0 && p < bp && v.Cells[y][x] == engine.ViewHidden {
+				best, bp = image.Point{x, y}, p
+			}
+		}
+	}
+	return Action{Kind: Open, X: best.X, Y: best.Y}, best.X >= 0
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"megamine/bot"
+	"megamine/engine"
+	"strings"
+	"time"
+)
+
+// bots plays games with bots without a window and prints how they did.
+func bots(args []string) error {
+	fs := flag.NewFlagSet("bot", flag.ContinueOnError)
+	names := fs.String("bot", "solver", "bots to run, separated by commas: "+strings.Join(bot.Names(), ", "))
+	n := fs.Int("n", 100, "games per bot")
+	preset := fs.String("preset", engine.Expert.Name, "beginner, intermediate or expert")
+	width := fs.Int("w", 0, "board width (overrides the preset)")
+	height := fs.Int("h", 0, "board height (overrides the preset)")
+	mines := fs.Int("m", 0, "number of mines (overrides the preset)")
+	seed := fs.Int64("seed", 1, "seed of the first game")
+	first := fs.String("first", engine.FirstClickXP.String(), "first click policy: xp, none, safe or zero")
+	noGuess := fs.Bool("ng", false, "deal boards that need no guess")
+	noFlag := fs.Bool("nf", false, "play without flags")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *n <= 0 {
+		return errors.New("n must be positive")
+	}
+	d, err := engine.ParsePreset(*preset)
+	if err != nil {
+		return err
+	}
+	if *width != 0 {
+		d.X = *width
+	}
+	if *height != 0 {
+		d.Y = *height
+	}
+	if *mines != 0 {
+		d.Mines = *mines
+	}
+	d = engine.DifficultyOf(d.X, d.Y, d.Mines)
+	rules := engine.DefaultRules()
+	if rules.FirstClick, err = engine.ParseFirstClick(*first); err != nil {
+		return err
+	}
+	rules.NoGuess = *noGuess
+	rules.NoFlag = *noFlag
+
+	for _, name := range strings.Split(*names, ",") {
+		sum, err := bot.Run(name, *n, d, rules, *seed)
+		if err != nil {
+			return err
+		}
+		perGame := sum.Time / time.Duration(sum.Games)
+		fmt.Printf("%s: %s, %d/%d won (%.1f%%), %v per game, %.1f 3BV/s\n",
+			name, d, sum.Won, sum.Games, sum.WinRate()*100, perGame, sum.BBBVs)
+	}
+	return nil
+}
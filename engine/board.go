@@ -0,0 +1,447 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math/rand"
+	"time"
+)
+
+type CellState int
+
+const (
+	CellMine CellState = 1 << iota
+	CellOpen
+	CellFlag
+	CellGuess
+)
+
+type GameState int
+
+const (
+	GameReady GameState = iota
+	GameActive
+	GameWin
+	GameDead
+)
+
+type Cell struct {
+	State  CellState
+	Nearby int
+}
+
+type Board struct {
+	X, Y      int
+	Mines     int
+	Board     [][]Cell
+	CellsLeft int
+	Flags     int
+	State     GameState
+	Seed      int64
+	Rules     Rules
+	// Solvable is set when the board was checked to need no guess.
+	Solvable bool
+	// Fixed boards keep their mines where they are on the first click.
+	Fixed  bool
+	Clicks Clicks
+	rand   *rand.Rand
+}
+
+// NewSeed returns a seed for a board nobody asked to reproduce.
+func NewSeed() int64 {
+	return time.Now().UnixNano()
+}
+
+// MaxSize is the most cells a board has across and down. Larger boards fit
+// neither MBF nor RMV files nor, drawn, a texture.
+const MaxSize = 255
+
+func tooLarge(x, y int) error {
+	if x > MaxSize || y > MaxSize {
+		return errors.New(fmt.Sprintf("Board size too large: %dx%d, at most %dx%d", x, y, MaxSize, MaxSize))
+	}
+	return nil
+}
+
+func NewBoard(x, y, mines int, seed int64) (*Board, error) {
+	if x < 9 || y < 9 {
+		return nil, errors.New(fmt.Sprintf("invalid size: %dx%d", x, y))
+	} else if mines < 10 {
+		return nil, errors.New(fmt.Sprintf("Too few mines"))
+	} else if err := tooLarge(x, y); err != nil {
+		return nil, err
+	}
+	size := x * y
+	if mines >= size {
+		return nil, errors.New("Too many mines")
+	}
+	b := &Board{
+		X:         x,
+		Y:         y,
+		Mines:     mines,
+		Flags:     0,
+		CellsLeft: x*y - mines,
+		State:     GameReady,
+		Seed:      seed,
+		Rules:     DefaultRules(),
+		rand:      rand.New(rand.NewSource(seed)),
+	}
+
+	b.Generate(b.rand)
+
+	return b, nil
+}
+
+// Generate places the mines using r and keeps r to deal the mines moved
+// by the first click. Boards generated from sources in the same state are
+// identical.
+func (b *Board) Generate(r *rand.Rand) {
+	b.rand = r
+	b.Board = make([][]Cell, b.Y)
+	for by := range b.Board {
+		b.Board[by] = make([]Cell, b.X)
+	}
+
+	for i := 0; i < b.Mines; i++ {
+		for {
+			my := r.Intn(b.Y)
+			mx := r.Intn(b.X)
+			if b.Board[my][mx].State&CellMine != 0 {
+				continue
+			}
+			b.addMine(mx, my)
+			break
+		}
+	}
+}
+
+func (b *Board) addMine(x, y int) {
+	b.Board[y][x].State |= CellMine
+	for yy := y - 1; yy <= y+1; yy++ {
+		for xx := x - 1; xx <= x+1; xx++ {
+			if xx < 0 || xx >= b.X || yy < 0 || yy >= b.Y {
+				continue
+			}
+			b.Board[yy][xx].Nearby++
+		}
+	}
+}
+
+func (b *Board) InBounds(x, y int) bool {
+	return x >= 0 && y >= 0 && x < b.X && y < b.Y
+}
+
+func (b *Board) Over() bool {
+	return b.State == GameWin || b.State == GameDead
+}
+
+func (b *Board) recursiveOpenCell(x, y int) {
+	for yy := y - 1; yy <= y+1; yy++ {
+		for xx := x - 1; xx <= x+1; xx++ {
+			if xx < 0 || yy < 0 || xx >= b.X || yy >= b.Y {
+				continue
+			} else if xx == x && yy == y {
+				continue
+			}
+			c := &b.Board[yy][xx]
+			if c.State&(CellOpen|CellFlag|CellGuess) != 0 {
+				continue
+			}
+			c.State |= CellOpen
+			b.CellsLeft--
+			if c.Nearby == 0 {
+				b.recursiveOpenCell(xx, yy)
+			}
+		}
+	}
+}
+
+func (b *Board) addMineOnLeftmost(skipX, skipY int) {
+	for y := 0; y < b.Y; y++ {
+		for x := 0; x < b.X; x++ {
+			c := &b.Board[y][x]
+			if c.State&CellMine != 0 || (x == skipX && y == skipY) {
+				continue
+			}
+			b.addMine(x, y)
+			return
+		}
+	}
+}
+
+func (b *Board) removeMine(x, y int) {
+	b.Board[y][x].State &^= CellMine
+	for yy := y - 1; yy <= y+1; yy++ {
+		for xx := x - 1; xx <= x+1; xx++ {
+			if xx < 0 || yy < 0 || xx >= b.X || yy >= b.Y {
+				continue
+			}
+			b.Board[yy][xx].Nearby--
+		}
+	}
+}
+
+func (b *Board) moveMineToLeftmost(x, y int) {
+	b.removeMine(x, y)
+	b.addMineOnLeftmost(x, y)
+}
+
+// moveMinesAway moves every mine on a cell avoid reports to a random
+// free cell it does not.
+func (b *Board) moveMinesAway(avoid func(x, y int) bool) {
+	for y := 0; y < b.Y; y++ {
+		for x := 0; x < b.X; x++ {
+			if !avoid(x, y) || b.Board[y][x].State&CellMine == 0 {
+				continue
+			}
+			b.removeMine(x, y)
+			for {
+				mx, my := b.rand.Intn(b.X), b.rand.Intn(b.Y)
+				if avoid(mx, my) || b.Board[my][mx].State&CellMine != 0 {
+					continue
+				}
+				b.addMine(mx, my)
+				break
+			}
+		}
+	}
+}
+
+// Dealt reports whether the mines are where they stay, the first cell
+// having been opened.
+func (b *Board) Dealt() bool {
+	return !b.firstOpen()
+}
+
+// NoGuessFailed reports whether the board was to need no guess but none
+// such was found in time, so it may need one.
+func (b *Board) NoGuessFailed() bool {
+	return b.Rules.NoGuess && b.Dealt() && !b.Fixed && !b.Solvable
+}
+
+// firstOpen reports whether no cell has been opened yet. Flags may
+// already have been placed.
+func (b *Board) firstOpen() bool {
+	return b.State == GameReady || (b.State == GameActive && b.CellsLeft == b.X*b.Y-b.Mines)
+}
+
+func (b *Board) dealFirstClick(x, y int) {
+	if b.Fixed {
+		return
+	}
+	if b.Rules.NoGuess {
+		b.generateNoGuess(x, y)
+		return
+	}
+	b.protectFirstClick(x, y)
+}
+
+func (b *Board) protectFirstClick(x, y int) {
+	switch b.Rules.FirstClick {
+	case FirstClickXP:
+		if b.Board[y][x].State&CellMine != 0 {
+			b.moveMineToLeftmost(x, y)
+		}
+	case FirstClickSafe:
+		b.moveMinesAway(func(mx, my int) bool {
+			return mx == x && my == y
+		})
+	case FirstClickZero:
+		b.moveMinesAway(b.openingAround(x, y))
+	}
+}
+
+func (b *Board) openCell(x, y int) {
+	c := &b.Board[y][x]
+	if c.State&(CellFlag|CellGuess|CellOpen) != 0 {
+		return
+	}
+	if b.firstOpen() {
+		b.dealFirstClick(x, y)
+		c = &b.Board[y][x]
+	}
+	c.State |= CellOpen
+	if c.State&CellMine != 0 {
+		b.State = GameDead
+		return
+	}
+	b.CellsLeft--
+	if b.State == GameReady {
+		b.State = GameActive
+	}
+	if c.Nearby == 0 {
+		b.recursiveOpenCell(x, y)
+	}
+	if b.CellsLeft == 0 && b.State == GameActive {
+		b.State = GameWin
+		b.flagMines()
+	}
+}
+
+// flagMines flags every mine of a won board, as the classic game does.
+// This is not a click, and how the game was played does not matter.
+func (b *Board) flagMines() {
+	for y := range b.Board {
+		for x := range b.Board[y] {
+			c := &b.Board[y][x]
+			if c.State&CellMine != 0 {
+				c.State = c.State&^CellGuess | CellFlag
+			}
+		}
+	}
+	b.Flags = b.Mines
+}
+
+// Open uncovers the cell at x, y. It reports whether the cell was opened.
+func (b *Board) Open(x, y int) bool {
+	if b.Over() || !b.InBounds(x, y) {
+		return false
+	}
+	b.Clicks.Left++
+	if b.Board[y][x].State&(CellFlag|CellGuess|CellOpen) != 0 {
+		return false
+	}
+	b.openCell(x, y)
+	b.Clicks.LeftEffective++
+	return true
+}
+
+// Chord opens every unflagged neighbour of an opened cell whose number
+// matches the flags around it. It reports whether the chord was valid.
+func (b *Board) Chord(x, y int) bool {
+	if b.Over() || !b.InBounds(x, y) {
+		return false
+	}
+	b.Clicks.Chord++
+	st := b.Board[y][x].State
+	if st&CellOpen == 0 {
+		return false
+	}
+	cnt := 0
+	for yy := y - 1; yy <= y+1; yy++ {
+		for xx := x - 1; xx <= x+1; xx++ {
+			if xx < 0 || xx >= b.X || yy < 0 || yy >= b.Y {
+				continue
+			}
+			st := b.Board[yy][xx].State
+			if st&CellFlag != 0 {
+				cnt++
+			}
+		}
+	}
+	if cnt != b.Board[y][x].Nearby {
+		return false
+	}
+	opened := false
+	for yy := y - 1; yy <= y+1; yy++ {
+		for xx := x - 1; xx <= x+1; xx++ {
+			if xx < 0 || xx >= b.X || yy < 0 || yy >= b.Y {
+				continue
+			} else if xx == x && yy == y {
+				continue
+			}
+			st := b.Board[yy][xx].State
+			if st&(CellFlag|CellOpen) != 0 {
+				continue
+			}
+			b.openCell(xx, yy)
+			opened = true
+		}
+	}
+	if opened {
+		b.Clicks.ChordEffective++
+	}
+	return true
+}
+
+// Flag cycles an unopened cell through flagged, guessed and plain.
+// It reports whether the cell changed.
+func (b *Board) Flag(x, y int) bool {
+	if b.Over() || !b.InBounds(x, y) {
+		return false
+	}
+	b.Clicks.Right++
+	cell := &b.Board[y][x]
+	if cell.State&CellOpen != 0 || b.Rules.NoFlag {
+		return false
+	}
+	switch {
+	case cell.State&CellGuess != 0:
+		cell.State ^= CellGuess
+	case cell.State&CellFlag != 0:
+		cell.State ^= CellFlag
+		cell.State |= CellGuess
+		b.Flags--
+	default:
+		cell.State ^= CellFlag
+		b.Flags++
+	}
+	if b.State == GameReady {
+		b.State = GameActive
+	}
+	b.Clicks.RightEffective++
+	return true
+}
+
+// FlagAround flags every unopened neighbour of an opened number when
+// there are only as many as the number. It is a right click that reports
+// whether anything was flagged.
+func (b *Board) FlagAround(x, y int) bool {
+	if b.Over() || !b.InBounds(x, y) {
+		return false
+	}
+	b.Clicks.Right++
+	if b.Board[y][x].State&CellOpen == 0 || b.Rules.NoFlag {
+		return false
+	}
+	var hidden []image.Point
+	unopened := 0
+	for yy := y - 1; yy <= y+1; yy++ {
+		for xx := x - 1; xx <= x+1; xx++ {
+			if !b.InBounds(xx, yy) || b.Board[yy][xx].State&CellOpen != 0 {
+				continue
+			}
+			unopened++
+			if b.Board[yy][xx].State&CellFlag == 0 {
+				hidden = append(hidden, image.Point{xx, yy})
+			}
+		}
+	}
+	if unopened != b.Board[y][x].Nearby || len(hidden) == 0 {
+		return false
+	}
+	for _, p := range hidden {
+		b.Board[p.Y][p.X].State &^= CellGuess
+		b.Board[p.Y][p.X].State |= CellFlag
+		b.Flags++
+	}
+	b.Clicks.RightEffective++
+	return true
+}
+
+// Restore readies a board decoded from a save to be played on. The mines
+// dealt on the first click depend on the seed as they did before.
+func (b *Board) Restore() error {
+	if err := tooLarge(b.X, b.Y); err != nil {
+		return err
+	}
+	if len(b.Board) != b.Y {
+		return errors.New("board does not match its size")
+	}
+	for _, row := range b.Board {
+		if len(row) != b.X {
+			return errors.New("board does not match its size")
+		}
+	}
+	b.rand = rand.New(rand.NewSource(b.Seed))
+	if b.Fixed {
+		return nil
+	}
+	nb, err := NewBoard(b.X, b.Y, b.Mines, b.Seed)
+	if err != nil {
+		return err
+	}
+	b.rand = nb.rand
+	return nil
+}
@@ -0,0 +1,280 @@
+package engine
+
+import (
+	"image"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// grid builds a board from rows of '*' for mines and '.' for safe cells.
+func grid(t *testing.T, rows ...string) *Board {
+	t.Helper()
+	var mines []image.Point
+	for y, r := range rows {
+		for x, c := range r {
+			if c == '*' {
+				mines = append(mines, image.Point{x, y})
+			}
+		}
+	}
+	b, err := NewBoardLayout(len(rows[0]), len(rows), mines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// seedWith returns a seed whose expert board has a mine at x, y or not,
+// as asked.
+func seedWith(t *testing.T, x, y int, mine bool) int64 {
+	t.Helper()
+	for seed := int64(1); seed < 1000; seed++ {
+		b, err := NewBoard(30, 16, 99, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (b.Board[y][x].State&CellMine != 0) == mine {
+			return seed
+		}
+	}
+	t.Fatal("no such seed")
+	return 0
+}
+
+func TestFirstClick(t *testing.T) {
+	const x, y = 15, 8
+	tests := []struct {
+		policy FirstClick
+		mine   bool
+		// check looks at the board after the click, next to the one dealt.
+		check func(t *testing.T, dealt, b *Board)
+	}{
+		{FirstClickNone, true, func(t *testing.T, dealt, b *Board) {
+			if b.State != GameDead {
+				t.Errorf("state %v, want dead", b.State)
+			}
+			if !slices.Equal(dealt.Layout(), b.Layout()) {
+				t.Error("mines moved")
+			}
+		}},
+		{FirstClickXP, true, func(t *testing.T, dealt, b *Board) {
+			// The mine goes to the first free cell from the top left.
+			free := dealt.Layout()
+			var first image.Point
+			for i := 0; ; i++ {
+				p := image.Point{i % b.X, i / b.X}
+				if !slices.Contains(free, p) {
+					first = p
+					break
+				}
+			}
+			if b.Board[first.Y][first.X].State&CellMine == 0 {
+				t.Errorf("no mine moved to %v", first)
+			}
+		}},
+		{FirstClickSafe, true, nil},
+		{FirstClickZero, true, func(t *testing.T, dealt, b *Board) {
+			if n := b.Board[y][x].Nearby; n != 0 {
+				t.Errorf("first click shows %d, want an opening", n)
+			}
+		}},
+		{FirstClickZero, false, func(t *testing.T, dealt, b *Board) {
+			if n := b.Board[y][x].Nearby; n != 0 {
+				t.Errorf("first click shows %d, want an opening", n)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			seed := seedWith(t, x, y, tt.mine)
+			dealt, _ := NewBoard(30, 16, 99, seed)
+			b, _ := NewBoard(30, 16, 99, seed)
+			b.Rules.FirstClick = tt.policy
+			b.Open(x, y)
+			if tt.policy != FirstClickNone && b.State == GameDead {
+				t.Fatal("first click hit a mine")
+			}
+			if n := len(b.Layout()); n != 99 {
+				t.Errorf("%d mines, want 99", n)
+			}
+			if tt.check != nil {
+				tt.check(t, dealt, b)
+			}
+		})
+	}
+}
+
+func TestParseFirstClick(t *testing.T) {
+	for _, f := range []FirstClick{FirstClickXP, FirstClickNone, FirstClickSafe, FirstClickZero} {
+		got, err := ParseFirstClick(f.String())
+		if err != nil || got != f {
+			t.Errorf("ParseFirstClick(%q) = %v, %v", f, got, err)
+		}
+	}
+	if _, err := ParseFirstClick("lucky"); err == nil {
+		t.Error("unknown policy parsed")
+	}
+}
+
+func TestChord(t *testing.T) {
+	b := grid(t,
+		"*...",
+		"....",
+		"....",
+		"...*")
+	b.Open(1, 1)
+	if b.Chord(1, 1) {
+		t.Fatal("chorded without the flag")
+	}
+	b.Flag(0, 0)
+	if !b.Chord(1, 1) {
+		t.Fatal("chord refused")
+	}
+	if b.Board[0][1].State&CellOpen == 0 {
+		t.Error("chord did not open the neighbours")
+	}
+	want := Clicks{Left: 1, Right: 1, Chord: 2, LeftEffective: 1, RightEffective: 1, ChordEffective: 1}
+	if b.Clicks != want {
+		t.Errorf("clicks %+v, want %+v", b.Clicks, want)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	b := grid(t,
+		"*...",
+		"....",
+		".*..",
+		"....")
+	b.Open(3, 0)
+	if b.State != GameActive {
+		t.Fatalf("state %v, want active", b.State)
+	}
+	if b.Board[0][1].State&CellOpen == 0 || b.Board[3][3].State&CellOpen == 0 {
+		t.Error("opening not spread")
+	}
+	if b.Board[3][0].State&CellOpen != 0 {
+		t.Error("opened behind the numbers")
+	}
+	for _, p := range []image.Point{{0, 1}, {0, 2}, {0, 3}, {1, 3}} {
+		b.Open(p.X, p.Y)
+	}
+	if b.State != GameWin {
+		t.Fatalf("state %v after the last safe cell, want won", b.State)
+	}
+
+	b = grid(t,
+		"*...",
+		"....",
+		".*..",
+		"....")
+	b.Open(3, 0)
+	b.Open(1, 2)
+	if b.State != GameDead {
+		t.Errorf("state %v after opening a mine, want dead", b.State)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	a, _ := NewBoard(30, 16, 99, 7)
+	b, _ := NewBoard(30, 16, 99, 7)
+	if !slices.Equal(a.Layout(), b.Layout()) {
+		t.Fatal("the same seed dealt two boards")
+	}
+
+	// Both the mines and those moved by the first click come from r.
+	for _, p := range []*Board{a, b} {
+		p.Rules.FirstClick = FirstClickZero
+		p.Generate(rand.New(rand.NewSource(1)))
+		p.Open(15, 8)
+	}
+	if !slices.Equal(a.Layout(), b.Layout()) {
+		t.Error("the same source dealt two boards")
+	}
+	c, _ := NewBoard(30, 16, 99, 7)
+	c.Rules.FirstClick = FirstClickZero
+	c.Open(15, 8)
+	if slices.Equal(a.Layout(), c.Layout()) {
+		t.Error("the seed of the board was used instead of the source")
+	}
+}
+
+func TestBoardSize(t *testing.T) {
+	tests := []struct {
+		x, y, mines int
+		ok          bool
+	}{
+		{9, 9, 10, true},
+		{MaxSize, MaxSize, 99, true},
+		{8, 9, 10, false},
+		{MaxSize + 1, 16, 99, false},
+		{30, 1200, 99, false},
+		{9, 9, 81, false},
+	}
+	for _, tt := range tests {
+		if _, err := NewBoard(tt.x, tt.y, tt.mines, 1); (err == nil) != tt.ok {
+			t.Errorf("NewBoard(%d, %d, %d) error %v", tt.x, tt.y, tt.mines, err)
+		}
+		if _, err := NewBoardLayout(tt.x, tt.y, nil); (err == nil) != (tt.x <= MaxSize && tt.y <= MaxSize) {
+			t.Errorf("NewBoardLayout(%d, %d) error %v", tt.x, tt.y, err)
+		}
+	}
+}
+
+func TestRewind(t *testing.T) {
+	b := grid(t,
+		"*...",
+		"....",
+		"....",
+		"...*")
+	s := b.Snapshot()
+	b.Flag(0, 0)
+	b.Open(0, 3)
+	b.Rewind(s)
+	if b.State != GameReady || b.Flags != 0 || b.CellsLeft != 14 {
+		t.Errorf("state %v, %d flags, %d left after rewind", b.State, b.Flags, b.CellsLeft)
+	}
+	if b.Board[0][0].State&CellFlag != 0 || b.Board[3][0].State&CellOpen != 0 {
+		t.Error("cells not rewound")
+	}
+}
+
+func TestWinFlagsMines(t *testing.T) {
+	b := grid(t,
+		"*..",
+		"...",
+		"..*")
+	b.Rules.NoFlag = true
+	for _, p := range []image.Point{{2, 0}, {0, 2}} {
+		b.Open(p.X, p.Y)
+	}
+	if b.State != GameWin {
+		t.Fatalf("state %v, want won", b.State)
+	}
+	for _, p := range b.Layout() {
+		if b.Board[p.Y][p.X].State&CellFlag == 0 {
+			t.Errorf("mine at %v not flagged", p)
+		}
+	}
+	if b.Flags != b.Mines {
+		t.Errorf("%d flags, want %d", b.Flags, b.Mines)
+	}
+	if b.Clicks.Right != 0 {
+		t.Errorf("%d right clicks counted for the flags", b.Clicks.Right)
+	}
+}
+
+func TestNoFlag(t *testing.T) {
+	b := grid(t,
+		"*..",
+		"...",
+		"...")
+	b.Rules.NoFlag = true
+	if b.Flag(0, 0) {
+		t.Error("flagged without flags")
+	}
+	b.Open(2, 2)
+	if b.FlagAround(1, 1) {
+		t.Error("flagged around without flags")
+	}
+}
@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Difficulty struct {
+	Name  string
+	X, Y  int
+	Mines int
+}
+
+var (
+	Beginner     = Difficulty{Name: "beginner", X: 9, Y: 9, Mines: 10}
+	Intermediate = Difficulty{Name: "intermediate", X: 16, Y: 16, Mines: 40}
+	Expert       = Difficulty{Name: "expert", X: 30, Y: 16, Mines: 99}
+)
+
+var Presets = []Difficulty{Beginner, Intermediate, Expert}
+
+// DifficultyOf returns the preset of that size, or a custom difficulty.
+func DifficultyOf(x, y, mines int) Difficulty {
+	for _, d := range Presets {
+		if d.X == x && d.Y == y && d.Mines == mines {
+			return d
+		}
+	}
+	return Difficulty{Name: "custom", X: x, Y: y, Mines: mines}
+}
+
+func ParsePreset(name string) (Difficulty, error) {
+	for _, d := range Presets {
+		if d.Name == name {
+			return d, nil
+		}
+	}
+	return Difficulty{}, errors.New(fmt.Sprintf("unknown preset: %s", name))
+}
+
+func (d Difficulty) Custom() bool {
+	return d.Name == "custom"
+}
+
+func (d Difficulty) String() string {
+	if d.Custom() {
+		return fmt.Sprintf("%dx%d/%d", d.X, d.Y, d.Mines)
+	}
+	return d.Name
+}
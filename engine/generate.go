@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"image"
+	"time"
+)
+
+// clear removes every mine from the board, keeping the marks.
+func (b *Board) clear() {
+	for y := range b.Board {
+		for x := range b.Board[y] {
+			b.Board[y][x].State &= CellFlag | CellGuess
+			b.Board[y][x].Nearby = 0
+		}
+	}
+}
+
+func (b *Board) copyCells() [][]Cell {
+	cells := make([][]Cell, b.Y)
+	for y := range cells {
+		cells[y] = make([]Cell, b.X)
+		copy(cells[y], b.Board[y])
+	}
+	return cells
+}
+
+// openingAround returns the cells that must stay free of mines for x, y
+// to open an area: the 3x3 square around it, or x, y alone when the board
+// leaves no room for the square.
+func (b *Board) openingAround(x, y int) func(mx, my int) bool {
+	if b.X*b.Y-b.Mines < 9 {
+		return func(mx, my int) bool {
+			return mx == x && my == y
+		}
+	}
+	return func(mx, my int) bool {
+		dx, dy := mx-x, my-y
+		return dx >= -1 && dx <= 1 && dy >= -1 && dy <= 1
+	}
+}
+
+// generateAround deals the mines again, keeping them off the opening
+// around x, y.
+func (b *Board) generateAround(x, y int) {
+	b.clear()
+	avoid := b.openingAround(x, y)
+	for i := 0; i < b.Mines; i++ {
+		for {
+			mx, my := b.rand.Intn(b.X), b.rand.Intn(b.Y)
+			if avoid(mx, my) || b.Board[my][mx].State&CellMine != 0 {
+				continue
+			}
+			b.addMine(mx, my)
+			break
+		}
+	}
+}
+
+// generateNoGuess looks for a board that can be solved from x, y without
+// guessing until the budget runs out. Given the same seed and first click
+// the same board is found, unless the budget runs out first.
+func (b *Board) generateNoGuess(x, y int) {
+	classic := b.copyCells()
+	deadline := time.Now().Add(b.Rules.NoGuessBudget)
+	for {
+		b.generateAround(x, y)
+		if b.solvable(x, y) {
+			b.Solvable = true
+			return
+		}
+		if !time.Now().Before(deadline) {
+			break
+		}
+	}
+	if b.Rules.NoGuessFallback == FallbackClassic {
+		b.Board = classic
+		b.protectFirstClick(x, y)
+	}
+}
+
+// reveal opens x, y on v the way the board would, and returns how many
+// cells it opened.
+func (b *Board) reveal(v *View, x, y int) int {
+	n := 0
+	stack := []image.Point{{x, y}}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if v.Opened(p.X, p.Y) {
+			continue
+		}
+		c := b.Board[p.Y][p.X]
+		v.Cells[p.Y][p.X] = c.Nearby
+		n++
+		if c.Nearby != 0 {
+			continue
+		}
+		for yy := p.Y - 1; yy <= p.Y+1; yy++ {
+			for xx := p.X - 1; xx <= p.X+1; xx++ {
+				if !b.InBounds(xx, yy) || v.Opened(xx, yy) {
+					continue
+				}
+				stack = append(stack, image.Point{xx, yy})
+			}
+		}
+	}
+	return n
+}
+
+// solvable reports whether opening x, y and then only cells the solver
+// proves safe clears the board.
+func (b *Board) solvable(x, y int) bool {
+	if b.Board[y][x].State&CellMine != 0 {
+		return false
+	}
+	v := newView(b.X, b.Y, b.Mines)
+	left := b.X*b.Y - b.Mines - b.reveal(v, x, y)
+	s := newSolver(v)
+	for left > 0 {
+		n := len(s.found)
+		if !s.step() {
+			return false
+		}
+		for _, d := range s.found[n:] {
+			if !d.Mine {
+				left -= b.reveal(v, d.X, d.Y)
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package engine
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// solve opens every cell Deduce proves safe until it finds none.
+func solve(b *Board) {
+	for !b.Over() {
+		opened := false
+		for _, d := range Deduce(b.View()) {
+			if !d.Mine && b.Board[d.Y][d.X].State&CellOpen == 0 {
+				b.Open(d.X, d.Y)
+				opened = true
+			}
+		}
+		if !opened {
+			return
+		}
+	}
+}
+
+func TestNoGuess(t *testing.T) {
+	const x, y = 8, 8
+	for seed := int64(1); seed <= 10; seed++ {
+		b, err := NewBoard(16, 16, 40, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.Rules.NoGuess = true
+		b.Open(x, y)
+		if !b.Solvable || b.NoGuessFailed() {
+			t.Fatalf("seed %d: no board found", seed)
+		}
+		if n := b.Board[y][x].Nearby; n != 0 {
+			t.Errorf("seed %d: first click shows %d, want an opening", seed, n)
+		}
+		layout := b.Layout()
+		solve(b)
+		if b.State != GameWin {
+			t.Errorf("seed %d: solving by deduction did not win", seed)
+		}
+
+		again, _ := NewBoard(16, 16, 40, seed)
+		again.Rules.NoGuess = true
+		again.Open(x, y)
+		if !slices.Equal(again.Layout(), layout) {
+			t.Errorf("seed %d: the same seed and click gave another board", seed)
+		}
+	}
+}
+
+func TestNoGuessFallback(t *testing.T) {
+	const x, y = 15, 8
+	start := seedWith(t, x, y, false)
+	for seed := start; seed < start+20; seed++ {
+		dealt, _ := NewBoard(30, 16, 99, seed)
+		if dealt.Board[y][x].State&CellMine != 0 {
+			continue
+		}
+		b, _ := NewBoard(30, 16, 99, seed)
+		b.Rules.NoGuess = true
+		b.Rules.NoGuessBudget = time.Nanosecond
+		b.Rules.NoGuessFallback = FallbackClassic
+		b.Open(x, y)
+		if b.Solvable {
+			// The first try happened to need no guess.
+			continue
+		}
+		if !slices.Equal(b.Layout(), dealt.Layout()) {
+			t.Errorf("seed %d: classic fallback moved the mines", seed)
+		}
+		if !b.NoGuessFailed() {
+			t.Errorf("seed %d: fallback board not reported", seed)
+		}
+		return
+	}
+	t.Skip("every board tried needed no guess")
+}
+
+func TestParseFallback(t *testing.T) {
+	for _, f := range []Fallback{FallbackRandom, FallbackClassic} {
+		got, err := ParseFallback(f.String())
+		if err != nil || got != f {
+			t.Errorf("ParseFallback(%q) = %v, %v", f, got, err)
+		}
+	}
+	if _, err := ParseFallback("retry"); err == nil {
+		t.Error("unknown fallback parsed")
+	}
+}
@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math/rand"
+)
+
+// NewBoardLayout returns a board with mines exactly where given. Its mines
+// stay put on the first click whatever the rules say.
+func NewBoardLayout(x, y int, mines []image.Point) (*Board, error) {
+	if x < 1 || y < 1 {
+		return nil, errors.New(fmt.Sprintf("invalid size: %dx%d", x, y))
+	} else if err := tooLarge(x, y); err != nil {
+		return nil, err
+	}
+	size := x * y
+	if len(mines) >= size {
+		return nil, errors.New("Too many mines")
+	}
+	b := &Board{
+		X:         x,
+		Y:         y,
+		Mines:     len(mines),
+		CellsLeft: size - len(mines),
+		State:     GameReady,
+		Rules:     DefaultRules(),
+		Fixed:     true,
+		rand:      rand.New(rand.NewSource(0)),
+	}
+	b.Board = make([][]Cell, b.Y)
+	for by := range b.Board {
+		b.Board[by] = make([]Cell, b.X)
+	}
+	for _, p := range mines {
+		if !b.InBounds(p.X, p.Y) {
+			return nil, errors.New(fmt.Sprintf("mine off the board: %d,%d", p.X, p.Y))
+		} else if b.Board[p.Y][p.X].State&CellMine != 0 {
+			return nil, errors.New(fmt.Sprintf("two mines at %d,%d", p.X, p.Y))
+		}
+		b.addMine(p.X, p.Y)
+	}
+	return b, nil
+}
+
+// Layout returns where the mines are, row by row.
+func (b *Board) Layout() []image.Point {
+	var mines []image.Point
+	for y := 0; y < b.Y; y++ {
+		for x := 0; x < b.X; x++ {
+			if b.Board[y][x].State&CellMine != 0 {
+				mines = append(mines, image.Point{x, y})
+			}
+		}
+	}
+	return mines
+}
@@ -0,0 +1,374 @@
+package engine
+
+import (
+	"errors"
+	"image"
+	"math"
+)
+
+// probabilityBudget bounds how many partial configurations are tried
+// before giving up on a board.
+const probabilityBudget = 1 << 22
+
+var (
+	ErrTooComplex   = errors.New("too many cases to count")
+	ErrContradicted = errors.New("the numbers and flags contradict each other")
+)
+
+// numberConstraint says that mines of the cells, indices into a
+// component, add up to need.
+type numberConstraint struct {
+	cells []int
+	need  int
+}
+
+// component is a group of frontier cells tied together by numbers.
+// counts[k] is how many ways k mines fit in it; mines[i][k] is how many
+// of those have cell i a mine.
+type component struct {
+	cells  []image.Point
+	rules  []numberConstraint
+	counts []float64
+	mines  [][]float64
+}
+
+// viewSafe marks cells already proven safe while counting.
+const viewSafe = -3
+
+// Probabilities returns the chance of a mine for every hidden cell of v,
+// with every arrangement of mines that fits the numbers equally likely.
+// Flags are taken as mines. Cells that are not hidden get -1.
+func Probabilities(v *View) ([][]float64, error) {
+	// Settling what can be deduced first breaks the rest into smaller
+	// groups to count.
+	orig := v
+	deduced := Deduce(v)
+	v = v.copy()
+	for _, d := range deduced {
+		if d.Mine {
+			v.Cells[d.Y][d.X] = ViewFlag
+		} else {
+			v.Cells[d.Y][d.X] = viewSafe
+		}
+	}
+	left := v.Mines
+	for y := 0; y < v.Y; y++ {
+		for x := 0; x < v.X; x++ {
+			if v.Cells[y][x] == ViewFlag {
+				left--
+			}
+		}
+	}
+
+	comps, floating, err := components(v)
+	if err != nil {
+		return nil, err
+	}
+	budget := probabilityBudget
+	for _, c := range comps {
+		if err := c.enumerate(&budget); err != nil {
+			return nil, err
+		}
+	}
+
+	// others[i] counts the ways the components other than i hold k mines.
+	all := []float64{1}
+	others := make([][]float64, len(comps))
+	for i := range comps {
+		others[i] = []float64{1}
+		for j, c := range comps {
+			if j != i {
+				others[i] = convolve(others[i], c.counts)
+			}
+		}
+		all = convolve(all, comps[i].counts)
+	}
+
+	// weight[k] is how likely it is that the frontier holds k mines: the
+	// ways it can, times the ways the rest fit in the floating cells.
+	// Binomials get large, so they are scaled by the largest one.
+	logs := make([]float64, len(all))
+	top := math.Inf(-1)
+	for k := range all {
+		logs[k] = math.Inf(-1)
+		if all[k] > 0 && left-k >= 0 && left-k <= floating {
+			logs[k] = logBinomial(floating, left-k)
+			top = math.Max(top, logs[k])
+		}
+	}
+	weight := make([]float64, len(all))
+	total := 0.0
+	for k := range all {
+		weight[k] = math.Exp(logs[k] - top)
+		total += all[k] * weight[k]
+	}
+	if total == 0 || math.IsInf(top, -1) {
+		return nil, ErrContradicted
+	}
+
+	p := make([][]float64, v.Y)
+	for y := range p {
+		p[y] = make([]float64, v.X)
+		for x := range p[y] {
+			p[y][x] = -1
+		}
+	}
+	for _, d := range deduced {
+		if orig.Cells[d.Y][d.X] == ViewHidden {
+			p[d.Y][d.X] = float64(boolInt(d.Mine))
+		}
+	}
+	for i, c := range comps {
+		for j, cell := range c.cells {
+			sum := 0.0
+			for k, n := range c.mines[j] {
+				for ko, m := range others[i] {
+					sum += n * m * weight[k+ko]
+				}
+			}
+			p[cell.Y][cell.X] = sum / total
+		}
+	}
+	if floating > 0 {
+		sum := 0.0
+		for k := range all {
+			sum += all[k] * weight[k] * float64(left-k) / float64(floating)
+		}
+		for y := 0; y < v.Y; y++ {
+			for x := 0; x < v.X; x++ {
+				if v.Cells[y][x] == ViewHidden && p[y][x] < 0 {
+					p[y][x] = sum / total
+				}
+			}
+		}
+	}
+	return p, nil
+}
+
+// certain is how close to 0 or 1 a counted chance must be to be sure.
+const certain = 1e-9
+
+// Certain counts every case of v, not trusting its flags, and returns the
+// cells that are safe in all of them or a mine in all of them. The Reason
+// of each is the numbers next to it.
+func Certain(v *View) ([]Deduction, error) {
+	u := v.copy()
+	for y := range u.Cells {
+		for x, c := range u.Cells[y] {
+			if c == ViewFlag {
+				u.Cells[y][x] = ViewHidden
+			}
+		}
+	}
+	p, err := Probabilities(u)
+	if err != nil {
+		return nil, err
+	}
+	var found []Deduction
+	for y := range p {
+		for x, q := range p[y] {
+			if q < 0 || (q > certain && q < 1-certain) {
+				continue
+			}
+			d := Deduction{X: x, Y: y, Mine: q > 0.5}
+			for yy := y - 1; yy <= y+1; yy++ {
+				for xx := x - 1; xx <= x+1; xx++ {
+					if u.InBounds(xx, yy) && u.Opened(xx, yy) {
+						d.Reason = append(d.Reason, image.Point{xx, yy})
+					}
+				}
+			}
+			found = append(found, d)
+		}
+	}
+	return found, nil
+}
+
+func logBinomial(n, k int) float64 {
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
+
+func convolve(a, b []float64) []float64 {
+	out := make([]float64, len(a)+len(b)-1)
+	for i, x := range a {
+		for j, y := range b {
+			out[i+j] += x * y
+		}
+	}
+	return out
+}
+
+// components splits the hidden cells next to numbers into groups that
+// do not constrain each other, and counts the hidden cells next to none.
+func components(v *View) ([]*component, int, error) {
+	// Every number becomes a constraint on its hidden neighbours.
+	type number struct {
+		cells []image.Point
+		need  int
+	}
+	var numbers []number
+	touching := map[image.Point][]int{}
+	for y := 0; y < v.Y; y++ {
+		for x := 0; x < v.X; x++ {
+			if !v.Opened(x, y) {
+				continue
+			}
+			n := number{need: v.Cells[y][x]}
+			for yy := y - 1; yy <= y+1; yy++ {
+				for xx := x - 1; xx <= x+1; xx++ {
+					if !v.InBounds(xx, yy) {
+						continue
+					}
+					switch v.Cells[yy][xx] {
+					case ViewFlag:
+						n.need--
+					case ViewHidden:
+						n.cells = append(n.cells, image.Point{xx, yy})
+					}
+				}
+			}
+			if n.need < 0 || n.need > len(n.cells) {
+				return nil, 0, ErrContradicted
+			}
+			if len(n.cells) == 0 {
+				continue
+			}
+			for _, p := range n.cells {
+				touching[p] = append(touching[p], len(numbers))
+			}
+			numbers = append(numbers, n)
+		}
+	}
+
+	floating := 0
+	for y := 0; y < v.Y; y++ {
+		for x := 0; x < v.X; x++ {
+			if v.Cells[y][x] == ViewHidden && touching[image.Point{x, y}] == nil {
+				floating++
+			}
+		}
+	}
+
+	// Walk from cell to number to cell, so cells come in an order that
+	// closes numbers early.
+	var comps []*component
+	seen := map[image.Point]bool{}
+	used := make([]bool, len(numbers))
+	for y := 0; y < v.Y; y++ {
+		for x := 0; x < v.X; x++ {
+			start := image.Point{x, y}
+			if touching[start] == nil || seen[start] {
+				continue
+			}
+			c := &component{}
+			index := map[image.Point]int{}
+			queue := []image.Point{start}
+			seen[start] = true
+			var ns []int
+			for len(queue) > 0 {
+				p := queue[0]
+				queue = queue[1:]
+				index[p] = len(c.cells)
+				c.cells = append(c.cells, p)
+				for _, i := range touching[p] {
+					if used[i] {
+						continue
+					}
+					used[i] = true
+					ns = append(ns, i)
+					for _, q := range numbers[i].cells {
+						if !seen[q] {
+							seen[q] = true
+							queue = append(queue, q)
+						}
+					}
+				}
+			}
+			for _, i := range ns {
+				r := numberConstraint{need: numbers[i].need}
+				for _, q := range numbers[i].cells {
+					r.cells = append(r.cells, index[q])
+				}
+				c.rules = append(c.rules, r)
+			}
+			comps = append(comps, c)
+		}
+	}
+	return comps, floating, nil
+}
+
+// enumerate counts every arrangement of mines in the component that fits
+// its numbers.
+func (c *component) enumerate(budget *int) error {
+	n := len(c.cells)
+	c.counts = make([]float64, n+1)
+	c.mines = make([][]float64, n)
+	for i := range c.mines {
+		c.mines[i] = make([]float64, n+1)
+	}
+	// For every rule, the mines placed so far and the cells still open.
+	placed := make([]int, len(c.rules))
+	open := make([]int, len(c.rules))
+	of := make([][]int, n)
+	for r, rule := range c.rules {
+		open[r] = len(rule.cells)
+		for _, i := range rule.cells {
+			of[i] = append(of[i], r)
+		}
+	}
+	assign := make([]bool, n)
+
+	var walk func(i, mines int) error
+	walk = func(i, mines int) error {
+		if *budget--; *budget < 0 {
+			return ErrTooComplex
+		}
+		if i == n {
+			c.counts[mines]++
+			for j, m := range assign {
+				if m {
+					c.mines[j][mines]++
+				}
+			}
+			return nil
+		}
+		for _, mine := range []bool{false, true} {
+			ok := true
+			for _, r := range of[i] {
+				open[r]--
+				if mine {
+					placed[r]++
+				}
+				need := c.rules[r].need
+				if placed[r] > need || placed[r]+open[r] < need {
+					ok = false
+				}
+			}
+			if ok {
+				assign[i] = mine
+				if err := walk(i+1, mines+boolInt(mine)); err != nil {
+					return err
+				}
+			}
+			for _, r := range of[i] {
+				open[r]++
+				if mine {
+					placed[r]--
+				}
+			}
+		}
+		assign[i] = false
+		return nil
+	}
+	return walk(0, 0)
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"errors"
+	"image"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// bruteProbabilities counts every arrangement of the mines left over the
+// hidden cells of v that fits the numbers.
+func bruteProbabilities(v *View) [][]float64 {
+	var hidden []image.Point
+	left := v.Mines
+	for y := 0; y < v.Y; y++ {
+		for x := 0; x < v.X; x++ {
+			switch v.Cells[y][x] {
+			case ViewHidden:
+				hidden = append(hidden, image.Point{x, y})
+			case ViewFlag:
+				left--
+			}
+		}
+	}
+	mine := make([][]bool, v.Y)
+	for y := range mine {
+		mine[y] = make([]bool, v.X)
+	}
+	fits := func() bool {
+		for y := 0; y < v.Y; y++ {
+			for x := 0; x < v.X; x++ {
+				if !v.Opened(x, y) {
+					continue
+				}
+				n := 0
+				for yy := y - 1; yy <= y+1; yy++ {
+					for xx := x - 1; xx <= x+1; xx++ {
+						if v.InBounds(xx, yy) && (v.Cells[yy][xx] == ViewFlag || mine[yy][xx]) {
+							n++
+						}
+					}
+				}
+				if n != v.Cells[y][x] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	counts := make([]float64, len(hidden))
+	total := 0.0
+	// place puts the mines still to place on hidden cells from i on.
+	var place func(i, left int)
+	place = func(i, left int) {
+		if left == 0 {
+			if fits() {
+				total++
+				for j, h := range hidden {
+					if mine[h.Y][h.X] {
+						counts[j]++
+					}
+				}
+			}
+			return
+		}
+		for j := i; j <= len(hidden)-left; j++ {
+			h := hidden[j]
+			mine[h.Y][h.X] = true
+			place(j+1, left-1)
+			mine[h.Y][h.X] = false
+		}
+	}
+	place(0, left)
+	p := make([][]float64, v.Y)
+	for y := range p {
+		p[y] = make([]float64, v.X)
+		for x := range p[y] {
+			p[y][x] = -1
+		}
+	}
+	for i, h := range hidden {
+		p[h.Y][h.X] = counts[i] / total
+	}
+	return p
+}
+
+func TestProbabilities(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		var mines []image.Point
+		for _, j := range r.Perm(6 * 4)[:5] {
+			mines = append(mines, image.Point{j % 6, j / 6})
+		}
+		b, err := NewBoardLayout(6, 4, mines)
+		if err != nil {
+			t.Fatal(err)
+		}
+		playSome(b, r, 1+r.Intn(4))
+		if b.Over() {
+			continue
+		}
+		v := b.View()
+		got, err := Probabilities(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := bruteProbabilities(v)
+		for y := range want {
+			for x := range want[y] {
+				if math.Abs(got[y][x]-want[y][x]) > 1e-9 {
+					t.Fatalf("board %d: %d,%d is %v, want %v", i, x, y, got[y][x], want[y][x])
+				}
+			}
+		}
+	}
+}
+
+func TestProbabilitiesContradicted(t *testing.T) {
+	if _, err := Probabilities(view(1,
+		"2.",
+		"..")); !errors.Is(err, ErrContradicted) {
+		t.Errorf("error %v, want %v", err, ErrContradicted)
+	}
+}
+
+func TestCertain(t *testing.T) {
+	// Taking the flag as a mine would make 0,0 safe.
+	v := view(1,
+		".1",
+		"F1")
+	if p, _ := Probabilities(v); p[0][0] != 0 {
+		t.Fatalf("flag not taken as a mine by Probabilities: %v", p)
+	}
+	got, err := Certain(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("trusted the flag: %+v", got)
+	}
+}
+
+// Deductions counted over every case must hold on the board, however
+// wrong its flags.
+func TestCertainSound(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for seed := int64(1); seed <= 200; seed++ {
+		b, err := NewBoard(9, 9, 10, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		playSome(b, r, 1+r.Intn(4))
+		if b.Over() {
+			continue
+		}
+		for i := 0; i < 3; i++ {
+			b.Flag(r.Intn(b.X), r.Intn(b.Y))
+		}
+		got, err := Certain(b.View())
+		if err != nil {
+			continue
+		}
+		for _, d := range got {
+			if mine := b.Board[d.Y][d.X].State&CellMine != 0; mine != d.Mine {
+				t.Fatalf("seed %d: %d,%d counted mine %v, is %v", seed, d.X, d.Y, d.Mine, mine)
+			}
+			for _, p := range d.Reason {
+				if b.Board[p.Y][p.X].State&CellOpen == 0 {
+					t.Errorf("seed %d: reason %v is not a number", seed, p)
+				}
+			}
+		}
+	}
+}
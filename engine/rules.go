@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type Fallback int
+
+const (
+	// FallbackRandom keeps the last board tried. It opens safely but may
+	// need a guess later.
+	FallbackRandom Fallback = iota
+	// FallbackClassic keeps the board dealt before the first click and
+	// applies the FirstClick policy to it.
+	FallbackClassic
+)
+
+var fallbackNames = []string{
+	FallbackRandom:  "random",
+	FallbackClassic: "classic",
+}
+
+func (f Fallback) String() string {
+	if f < 0 || int(f) >= len(fallbackNames) {
+		return "unknown"
+	}
+	return fallbackNames[f]
+}
+
+// ParseFallback returns the fallback named by s.
+func ParseFallback(s string) (Fallback, error) {
+	for i, name := range fallbackNames {
+		if s == name {
+			return Fallback(i), nil
+		}
+	}
+	return 0, errors.New(fmt.Sprintf("unknown no guess fallback: %s", s))
+}
+
+// FirstClick decides what happens when the first cell opened is not a
+// safe one.
+type FirstClick int
+
+const (
+	// FirstClickXP moves a mine under the first click to the first free
+	// cell from the top left, as Windows XP does.
+	FirstClickXP FirstClick = iota
+	// FirstClickNone lets the first click hit a mine.
+	FirstClickNone
+	// FirstClickSafe moves a mine under the first click to a random
+	// free cell.
+	FirstClickSafe
+	// FirstClickZero moves every mine out of the 3x3 square around the
+	// first click so it always opens an area.
+	FirstClickZero
+)
+
+var firstClickNames = []string{
+	FirstClickXP:   "xp",
+	FirstClickNone: "none",
+	FirstClickSafe: "safe",
+	FirstClickZero: "zero",
+}
+
+func (f FirstClick) String() string {
+	if f < 0 || int(f) >= len(firstClickNames) {
+		return "unknown"
+	}
+	return firstClickNames[f]
+}
+
+// ParseFirstClick returns the policy named by s.
+func ParseFirstClick(s string) (FirstClick, error) {
+	for i, name := range firstClickNames {
+		if s == name {
+			return FirstClick(i), nil
+		}
+	}
+	return 0, errors.New(fmt.Sprintf("unknown first click policy: %s", s))
+}
+
+const DefaultNoGuessBudget = 2 * time.Second
+
+// Rules are the options a board is played under. They may be changed
+// until the first cell is opened.
+type Rules struct {
+	FirstClick FirstClick
+	// NoGuess deals the mines on the first click so the whole board can
+	// be solved by deduction from there. The first click always opens
+	// an area then, whatever FirstClick says.
+	NoGuess bool
+	// NoGuessBudget bounds the time spent looking for such a board, after
+	// which NoGuessFallback decides what is played.
+	NoGuessBudget   time.Duration
+	NoGuessFallback Fallback
+	// NoFlag forbids flagging and marking cells.
+	NoFlag bool
+}
+
+func DefaultRules() Rules {
+	return Rules{
+		NoGuessBudget: DefaultNoGuessBudget,
+	}
+}
@@ -0,0 +1,213 @@
+package engine
+
+import "image"
+
+// Deduction is a cell whose content follows from the numbers in Reason.
+// Reason is empty when the deduction follows from the mine count alone.
+type Deduction struct {
+	X, Y   int
+	Mine   bool
+	Reason []image.Point
+}
+
+const (
+	solveUnknown = iota
+	solveMine
+	solveSafe
+)
+
+type constraint struct {
+	at    image.Point
+	cells []image.Point
+	mines int
+}
+
+type solver struct {
+	v     *View
+	known [][]int
+	found []Deduction
+}
+
+func newSolver(v *View) *solver {
+	s := &solver{
+		v:     v,
+		known: make([][]int, v.Y),
+	}
+	for y := range s.known {
+		s.known[y] = make([]int, v.X)
+	}
+	return s
+}
+
+// Deduce returns every hidden cell of v that is provably safe or provably
+// a mine. Flags are not trusted.
+func Deduce(v *View) []Deduction {
+	s := newSolver(v)
+	for s.step() {
+	}
+	return s.found
+}
+
+func (s *solver) mark(p image.Point, mine bool, reason []image.Point) bool {
+	if s.known[p.Y][p.X] != solveUnknown {
+		return false
+	}
+	if mine {
+		s.known[p.Y][p.X] = solveMine
+	} else {
+		s.known[p.Y][p.X] = solveSafe
+	}
+	s.found = append(s.found, Deduction{
+		X:      p.X,
+		Y:      p.Y,
+		Mine:   mine,
+		Reason: reason,
+	})
+	return true
+}
+
+func (s *solver) hidden(x, y int) bool {
+	return !s.v.Opened(x, y) && s.known[y][x] != solveSafe
+}
+
+// constraints lists, for every number next to an undecided cell, the
+// undecided cells around it and how many of them are mines.
+func (s *solver) constraints() []constraint {
+	var cs []constraint
+	for y := 0; y < s.v.Y; y++ {
+		for x := 0; x < s.v.X; x++ {
+			if !s.v.Opened(x, y) {
+				continue
+			}
+			c := constraint{
+				at:    image.Point{x, y},
+				mines: s.v.Cells[y][x],
+			}
+			for yy := y - 1; yy <= y+1; yy++ {
+				for xx := x - 1; xx <= x+1; xx++ {
+					if !s.v.InBounds(xx, yy) || !s.hidden(xx, yy) {
+						continue
+					}
+					if s.known[yy][xx] == solveMine {
+						c.mines--
+						continue
+					}
+					c.cells = append(c.cells, image.Point{xx, yy})
+				}
+			}
+			if len(c.cells) > 0 {
+				cs = append(cs, c)
+			}
+		}
+	}
+	return cs
+}
+
+// step runs one pass of every rule and reports whether anything new was
+// found.
+func (s *solver) step() bool {
+	progress := false
+	cs := s.constraints()
+	for _, c := range cs {
+		switch c.mines {
+		case 0:
+			for _, p := range c.cells {
+				progress = s.mark(p, false, []image.Point{c.at}) || progress
+			}
+		case len(c.cells):
+			for _, p := range c.cells {
+				progress = s.mark(p, true, []image.Point{c.at}) || progress
+			}
+		}
+	}
+	if progress {
+		return true
+	}
+
+	for i := range cs {
+		for j := range cs {
+			if i == j || !near(cs[i].at, cs[j].at) {
+				continue
+			}
+			progress = s.subset(&cs[i], &cs[j]) || progress
+		}
+	}
+	if progress {
+		return true
+	}
+
+	return s.count()
+}
+
+func near(a, b image.Point) bool {
+	dx, dy := a.X-b.X, a.Y-b.Y
+	return dx >= -2 && dx <= 2 && dy >= -2 && dy <= 2
+}
+
+func contains(ps []image.Point, p image.Point) bool {
+	for _, q := range ps {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
+
+// subset compares two overlapping numbers. The shared cells hold at
+// least as many mines as a needs beyond the cells only a sees, and at
+// most as many as a needs at all; when either bound decides the cells
+// only b sees, they are marked.
+func (s *solver) subset(a, b *constraint) bool {
+	var diff []image.Point
+	shared := 0
+	for _, p := range b.cells {
+		if contains(a.cells, p) {
+			shared++
+		} else {
+			diff = append(diff, p)
+		}
+	}
+	if len(diff) == 0 || shared == 0 {
+		return false
+	}
+	least := a.mines - (len(a.cells) - shared)
+	most := min(shared, a.mines)
+	reason := []image.Point{a.at, b.at}
+	progress := false
+	switch {
+	case least > 0 && least == b.mines:
+		for _, p := range diff {
+			progress = s.mark(p, false, reason) || progress
+		}
+	case b.mines-most == len(diff):
+		for _, p := range diff {
+			progress = s.mark(p, true, reason) || progress
+		}
+	}
+	return progress
+}
+
+// count settles every undecided cell when the remaining mines are
+// either none of them or all of them.
+func (s *solver) count() bool {
+	left := s.v.Mines
+	var cells []image.Point
+	for y := 0; y < s.v.Y; y++ {
+		for x := 0; x < s.v.X; x++ {
+			switch {
+			case s.known[y][x] == solveMine:
+				left--
+			case s.hidden(x, y):
+				cells = append(cells, image.Point{x, y})
+			}
+		}
+	}
+	if len(cells) == 0 || (left != 0 && left != len(cells)) {
+		return false
+	}
+	progress := false
+	for _, p := range cells {
+		progress = s.mark(p, left != 0, nil) || progress
+	}
+	return progress
+}
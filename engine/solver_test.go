@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"image"
+	"math/rand"
+	"testing"
+)
+
+// view builds a view from rows of digits for opened cells, 'F' for flags
+// and '.' for hidden cells.
+func view(mines int, rows ...string) *View {
+	v := newView(len(rows[0]), len(rows), mines)
+	for y, r := range rows {
+		for x, c := range r {
+			switch {
+			case c >= '0' && c <= '8':
+				v.Cells[y][x] = int(c - '0')
+			case c == 'F':
+				v.Cells[y][x] = ViewFlag
+			}
+		}
+	}
+	return v
+}
+
+func TestDeduce(t *testing.T) {
+	tests := []struct {
+		name  string
+		v     *View
+		mines []image.Point
+		safe  []image.Point
+	}{
+		{"single number", view(1,
+			"1.1"), []image.Point{{1, 0}}, nil},
+		{"flag not trusted", view(2,
+			"F1..",
+			"11..",
+		), []image.Point{{0, 0}}, []image.Point{{2, 0}, {2, 1}}},
+		{"pair subset", view(2,
+			"...",
+			"121",
+		), []image.Point{{0, 0}, {2, 0}}, []image.Point{{1, 0}}},
+		{"mine count", view(0,
+			"..",
+			".."), nil, []image.Point{{0, 0}, {1, 0}, {0, 1}, {1, 1}}},
+		{"nothing follows", view(1,
+			"..",
+			"1."), nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[image.Point]bool{}
+			for _, d := range Deduce(tt.v) {
+				got[image.Point{d.X, d.Y}] = d.Mine
+			}
+			for _, p := range tt.mines {
+				if mine, ok := got[p]; !ok || !mine {
+					t.Errorf("%v not found a mine", p)
+				}
+				delete(got, p)
+			}
+			for _, p := range tt.safe {
+				if mine, ok := got[p]; !ok || mine {
+					t.Errorf("%v not found safe", p)
+				}
+				delete(got, p)
+			}
+			for p, mine := range got {
+				t.Errorf("unexpected deduction %v, mine %v", p, mine)
+			}
+		})
+	}
+}
+
+// playSome opens random safe cells of b until n are opened or the game
+// is over, keeping it alive.
+func playSome(b *Board, r *rand.Rand, n int) {
+	for i := 0; i < n && !b.Over(); i++ {
+		x, y := r.Intn(b.X), r.Intn(b.Y)
+		if b.Board[y][x].State&CellMine == 0 {
+			b.Open(x, y)
+		}
+	}
+}
+
+// Every deduction must hold on the board it was made from.
+func TestDeduceSound(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for seed := int64(0); seed < 200; seed++ {
+		b, err := NewBoard(16, 16, 40, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		playSome(b, r, 1+r.Intn(6))
+		for _, d := range Deduce(b.View()) {
+			if mine := b.Board[d.Y][d.X].State&CellMine != 0; mine != d.Mine {
+				t.Fatalf("seed %d: %d,%d deduced mine %v, is %v", seed, d.X, d.Y, d.Mine, mine)
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"image"
+	"time"
+)
+
+// Clicks counts the actions taken on a board. Effective ones changed it.
+type Clicks struct {
+	Left, Right, Chord                            int
+	LeftEffective, RightEffective, ChordEffective int
+}
+
+func (c Clicks) Total() int {
+	return c.Left + c.Right + c.Chord
+}
+
+func (c Clicks) Effective() int {
+	return c.LeftEffective + c.RightEffective + c.ChordEffective
+}
+
+// Stats are the measures of a game by which players compare themselves.
+type Stats struct {
+	// BBBV is the 3BV of the board, the fewest clicks that clear it.
+	// Solved is how much of it was done.
+	BBBV   int
+	Solved int
+	Clicks Clicks
+	Time   time.Duration
+	Won    bool
+}
+
+func (b *Board) Stats(elapsed time.Duration) Stats {
+	total, solved := b.bbbv()
+	return Stats{
+		BBBV:   total,
+		Solved: solved,
+		Clicks: b.Clicks,
+		Time:   elapsed,
+		Won:    b.State == GameWin,
+	}
+}
+
+// bbbv counts every opening once and every safe cell no opening reveals,
+// and how many of those were opened.
+func (b *Board) bbbv() (total, solved int) {
+	seen := make([][]bool, b.Y)
+	for y := range seen {
+		seen[y] = make([]bool, b.X)
+	}
+	for y := 0; y < b.Y; y++ {
+		for x := 0; x < b.X; x++ {
+			c := b.Board[y][x]
+			if seen[y][x] || c.State&CellMine != 0 || c.Nearby != 0 {
+				continue
+			}
+			total++
+			if b.floodOpening(seen, x, y) {
+				solved++
+			}
+		}
+	}
+	for y := 0; y < b.Y; y++ {
+		for x := 0; x < b.X; x++ {
+			c := b.Board[y][x]
+			if seen[y][x] || c.State&CellMine != 0 {
+				continue
+			}
+			total++
+			if c.State&CellOpen != 0 {
+				solved++
+			}
+		}
+	}
+	return
+}
+
+// floodOpening marks the opening at x, y and its border as seen, and
+// reports whether it was opened. Opening a number on its border does not
+// open it.
+func (b *Board) floodOpening(seen [][]bool, x, y int) bool {
+	opened := false
+	stack := []image.Point{{x, y}}
+	seen[y][x] = true
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		c := b.Board[p.Y][p.X]
+		if c.Nearby != 0 {
+			continue
+		}
+		if c.State&CellOpen != 0 {
+			opened = true
+		}
+		for yy := p.Y - 1; yy <= p.Y+1; yy++ {
+			for xx := p.X - 1; xx <= p.X+1; xx++ {
+				if !b.InBounds(xx, yy) || seen[yy][xx] {
+					continue
+				}
+				seen[yy][xx] = true
+				stack = append(stack, image.Point{xx, yy})
+			}
+		}
+	}
+	return opened
+}
+
+func (s Stats) seconds() float64 {
+	return s.Time.Seconds()
+}
+
+// BBBVPerSecond is the 3BV done per second.
+func (s Stats) BBBVPerSecond() float64 {
+	if s.Time <= 0 {
+		return 0
+	}
+	return float64(s.Solved) / s.seconds()
+}
+
+// IOE is the 3BV done per click.
+func (s Stats) IOE() float64 {
+	if s.Clicks.Total() == 0 {
+		return 0
+	}
+	return float64(s.Solved) / float64(s.Clicks.Total())
+}
+
+// Correctness is the share of clicks that changed the board.
+func (s Stats) Correctness() float64 {
+	if s.Clicks.Total() == 0 {
+		return 0
+	}
+	return float64(s.Clicks.Effective()) / float64(s.Clicks.Total())
+}
+
+// RQP is the time divided by the 3BV per second. Lower is better.
+func (s Stats) RQP() float64 {
+	if s.Solved == 0 {
+		return 0
+	}
+	return s.seconds() * s.seconds() / float64(s.Solved)
+}
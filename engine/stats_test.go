@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"image"
+	"testing"
+)
+
+func TestBBBV(t *testing.T) {
+	tests := []struct {
+		name  string
+		rows  []string
+		open  []image.Point
+		total int
+		// solved is after opening the cells of open.
+		solved int
+	}{
+		{"one opening", []string{
+			"*..",
+			"...",
+			"..."}, nil, 1, 0},
+		{"opening and a lone number", []string{
+			"*.*",
+			"...",
+			"..."}, []image.Point{{1, 0}}, 2, 1},
+		{"no opening", []string{
+			".*."}, []image.Point{{0, 0}}, 2, 1},
+		{"opening opened", []string{
+			"*.*",
+			"...",
+			"..."}, []image.Point{{1, 2}}, 2, 1},
+		{"border number opened", []string{
+			".........",
+			".........",
+			".........",
+			".........",
+			".........",
+			".........",
+			".........",
+			".........",
+			"*.......*"}, []image.Point{{7, 7}}, 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := grid(t, tt.rows...)
+			for _, p := range tt.open {
+				b.Open(p.X, p.Y)
+			}
+			total, solved := b.bbbv()
+			if total != tt.total || solved != tt.solved {
+				t.Errorf("3BV %d/%d, want %d/%d", solved, total, tt.solved, tt.total)
+			}
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	s := Stats{
+		BBBV:   20,
+		Solved: 10,
+		Clicks: Clicks{Left: 15, Right: 3, Chord: 2, LeftEffective: 10, RightEffective: 3, ChordEffective: 1},
+		Time:   5e9,
+	}
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"3BV/s", s.BBBVPerSecond(), 2},
+		{"IOE", s.IOE(), 0.5},
+		{"correctness", s.Correctness(), 0.7},
+		{"RQP", s.RQP(), 2.5},
+	}
+	for _, tt := range tests {
+		if d := tt.got - tt.want; d > 1e-9 || d < -1e-9 {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+	if (Stats{}).BBBVPerSecond() != 0 || (Stats{}).IOE() != 0 {
+		t.Error("empty stats are not zero")
+	}
+}
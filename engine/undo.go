@@ -0,0 +1,34 @@
+package engine
+
+// Snapshot is the state of a board at one point of a game, to go back to.
+type Snapshot struct {
+	cells     [][]Cell
+	cellsLeft int
+	flags     int
+	state     GameState
+	solvable  bool
+}
+
+func (b *Board) Snapshot() Snapshot {
+	return Snapshot{
+		cells:     b.copyCells(),
+		cellsLeft: b.CellsLeft,
+		flags:     b.Flags,
+		state:     b.State,
+		solvable:  b.Solvable,
+	}
+}
+
+// Rewind puts the board back to the snapshot. Clicks are kept, as they
+// were made all the same.
+func (b *Board) Rewind(s Snapshot) {
+	b.Board = make([][]Cell, b.Y)
+	for y := range b.Board {
+		b.Board[y] = make([]Cell, b.X)
+		copy(b.Board[y], s.cells[y])
+	}
+	b.CellsLeft = s.cellsLeft
+	b.Flags = s.flags
+	b.State = s.state
+	b.Solvable = s.solvable
+}
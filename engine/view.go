@@ -0,0 +1,64 @@
+package engine
+
+// Cells of a View hold the number of an opened cell or one of these.
+const (
+	ViewHidden = -1
+	ViewFlag   = -2
+)
+
+// View is what a player can see of a board: numbers of opened cells,
+// flags and nothing else.
+type View struct {
+	X, Y  int
+	Mines int
+	Cells [][]int
+}
+
+func newView(x, y, mines int) *View {
+	v := &View{
+		X:     x,
+		Y:     y,
+		Mines: mines,
+		Cells: make([][]int, y),
+	}
+	for vy := range v.Cells {
+		v.Cells[vy] = make([]int, x)
+		for vx := range v.Cells[vy] {
+			v.Cells[vy][vx] = ViewHidden
+		}
+	}
+	return v
+}
+
+func (b *Board) View() *View {
+	v := newView(b.X, b.Y, b.Mines)
+	for y := 0; y < b.Y; y++ {
+		for x := 0; x < b.X; x++ {
+			c := b.Board[y][x]
+			switch {
+			case c.State&CellOpen != 0 && c.State&CellMine == 0:
+				v.Cells[y][x] = c.Nearby
+			case c.State&CellFlag != 0:
+				v.Cells[y][x] = ViewFlag
+			}
+		}
+	}
+	return v
+}
+
+func (v *View) InBounds(x, y int) bool {
+	return x >= 0 && y >= 0 && x < v.X && y < v.Y
+}
+
+// Opened reports whether the cell at x, y shows a number.
+func (v *View) Opened(x, y int) bool {
+	return v.Cells[y][x] >= 0
+}
+
+func (v *View) copy() *View {
+	c := newView(v.X, v.Y, v.Mines)
+	for y := range c.Cells {
+		copy(c.Cells[y], v.Cells[y])
+	}
+	return c
+}
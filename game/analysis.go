@@ -0,0 +1,65 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"megamine/engine"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// odds returns the chance of a mine in every hidden cell, counted once
+// per change of the board.
+func (b *Board) odds() ([][]float64, error) {
+	if b.oddsCells == nil && b.oddsErr == nil {
+		b.oddsCells, b.oddsErr = engine.Probabilities(b.View())
+	}
+	return b.oddsCells, b.oddsErr
+}
+
+func oddsColor(p float64) color.RGBA {
+	return color.RGBA{
+		R: uint8(0x80 * p),
+		G: uint8(0x80 * (1 - p)),
+		A: 0x80,
+	}
+}
+
+func oddsText(p float64) string {
+	switch {
+	case p < 0.005:
+		return "0"
+	case p > 0.995:
+		return "M"
+	}
+	return fmt.Sprint(int(p*100 + 0.5))
+}
+
+// DrawAnalysis tints every hidden cell by its chance of a mine and prints
+// it in percent.
+func DrawAnalysis(s *ebiten.Image) {
+	if !Game.Analysis {
+		return
+	}
+	b := GameBoard
+	odds, err := b.odds()
+	if err != nil {
+		y := b.Pos.Y + b.Y*16 - lineHeight
+		vector.DrawFilledRect(s, float32(b.Pos.X), float32(y),
+			float32(b.X*16), lineHeight, replayBarColor, false)
+		ebitenutil.DebugPrintAt(s, err.Error(), b.Pos.X+2, y)
+		return
+	}
+	for y, row := range odds {
+		for x, p := range row {
+			if p < 0 {
+				continue
+			}
+			px, py := b.Pos.X+x*16, b.Pos.Y+y*16
+			vector.DrawFilledRect(s, float32(px), float32(py), 16, 16, oddsColor(p), false)
+			ebitenutil.DebugPrintAt(s, oddsText(p), px+1, py)
+		}
+	}
+}
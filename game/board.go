@@ -1,24 +1,12 @@
 package game
 
 import (
-	"errors"
-	"fmt"
 	"image"
-	"math/rand"
-	"time"
+	"megamine/engine"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
-type CellState int
-
-const (
-	CellMine = 1 << iota
-	CellOpen
-	CellFlag
-	CellGuess
-)
-
 type XrayKind int
 
 const (
@@ -28,81 +16,54 @@ const (
 	XrayDisabled
 )
 
-type Cell struct {
-	State  CellState
-	Nearby int
-}
-
 type Board struct {
-	X, Y         int
-	Mines        int
-	Board        [][]Cell
+	*engine.Board
 	Pos          image.Point
-	CellsLeft    int
-	Flags        int
 	XrayX, XrayY int
 	XrayMode     XrayKind
-	img          *ebiten.Image
+	// A chord is tried once per press of both buttons, where they went
+	// down. Moving on with them held only shows the cells around.
+	chording bool
+	chordAt  image.Point
+	chordOK  bool
+	// Practice games can be undone and never count towards the scores.
+	Practice   bool
+	undo, redo []engine.Snapshot
+	// logged is set once the game is in the history, so practice games
+	// ended again after an undo are not logged twice.
+	logged bool
+	// Assisted games had a hint and never count towards the scores.
+	Assisted  bool
+	hint      *engine.Deduction
+	hintText  string
+	oddsCells [][]float64
+	oddsErr   error
+	img       *ebiten.Image
 }
 
 var GameBoard *Board
 
-func NewBoard(x, y, mines int) (*Board, error) {
-	if x < 9 || y < 9 {
-		return nil, errors.New(fmt.Sprintf("invalid size: %ux%u", x, y))
-	} else if mines < 10 {
-		return nil, errors.New(fmt.Sprintf("Too few mines"))
-	}
-	size := x * y
-	if y != 0 && size/y != x {
-		return nil, errors.New("Board size too large")
+func NewBoard(x, y, mines int, seed int64) (*Board, error) {
+	eb, err := engine.NewBoard(x, y, mines, seed)
+	if err != nil {
+		return nil, err
 	}
+	eb.Rules = Settings.Rules
+	return newBoard(eb), nil
+}
+
+func newBoard(eb *engine.Board) *Board {
 	b := &Board{
-		X:         x,
-		Y:         y,
-		Mines:     mines,
-		Flags:     0,
-		CellsLeft: x*y - mines,
+		Board: eb,
 	}
-
-	b.Generate()
 	b.img = ebiten.NewImage(b.X*16, b.Y*16)
 	b.renderAll()
-
-	return b, nil
-}
-
-func (b *Board) Generate() {
-	rand.Seed(time.Now().UnixNano())
-
-	b.Board = make([][]Cell, b.Y)
-	for by := range b.Board {
-		b.Board[by] = make([]Cell, b.X)
-	}
-
-	for i := 0; i < b.Mines; i++ {
-		for {
-			my := rand.Intn(b.Y)
-			mx := rand.Intn(b.X)
-			if b.Board[my][mx].State&CellMine != 0 {
-				continue
-			}
-			b.Board[my][mx].State |= CellMine
-			for yy := my - 1; yy <= my+1; yy++ {
-				for xx := mx - 1; xx <= mx+1; xx++ {
-					if xx < 0 || xx >= b.X || yy < 0 || yy >= b.Y {
-						continue
-					}
-					b.Board[yy][xx].Nearby++
-				}
-			}
-			break
-		}
-	}
+	return b
 }
 
-func InitBoard() (err error) {
-	GameBoard, err = NewBoard(30*1, 16*1, 99*1)
+func InitBoard(seed int64) (err error) {
+	d := Settings.Difficulty
+	GameBoard, err = NewBoard(d.X, d.Y, d.Mines, seed)
 	if err != nil {
 		return
 	}
@@ -113,88 +74,7 @@ func (b *Board) UpdatePos() {
 	gw, gh := Game.Layout(ebiten.WindowSize())
 	iw, ih := 16*b.X, 16*b.Y
 	b.Pos.X = (gw - iw) / 2
-	b.Pos.Y = (gh - ih) - b.Pos.X
-}
-
-func (b *Board) recursiveOpenCell(x, y int) {
-	for yy := y - 1; yy <= y+1; yy++ {
-		for xx := x - 1; xx <= x+1; xx++ {
-			if xx < 0 || yy < 0 || xx >= b.X || yy >= b.Y {
-				continue
-			} else if xx == x && yy == y {
-				continue
-			}
-			c := &b.Board[yy][xx]
-			if c.State&(CellOpen|CellFlag|CellGuess) != 0 {
-				continue
-			}
-			c.State |= CellOpen
-			b.CellsLeft--
-			if c.Nearby == 0 {
-				b.recursiveOpenCell(xx, yy)
-			}
-		}
-	}
-}
-
-func (b *Board) addMineOnLeftmost() {
-	for y := 0; y < b.Y; y++ {
-		for x := 0; x < b.X; x++ {
-			c := &b.Board[y][x]
-			if c.State&CellMine != 0 {
-				continue
-			}
-			c.State |= CellMine
-			for yy := y - 1; yy <= y+1; yy++ {
-				for xx := x - 1; xx <= x+1; xx++ {
-					if xx < 0 || yy < 0 || xx >= b.X || yy >= b.Y {
-						continue
-					}
-					b.Board[yy][xx].Nearby++
-				}
-			}
-			return
-		}
-	}
-}
-
-func (b *Board) moveMineToLeftmost(x, y int) {
-	b.Board[y][x].State ^= CellMine
-	for yy := y - 1; yy <= y+1; yy++ {
-		for xx := x - 1; xx <= x+1; xx++ {
-			if xx < 0 || yy < 0 || xx >= b.X || yy >= b.Y {
-				continue
-			}
-			b.Board[yy][xx].Nearby--
-		}
-	}
-	b.addMineOnLeftmost()
-}
-
-func (b *Board) openCell(x, y int) {
-	c := &b.Board[y][x]
-	if c.State&(CellFlag|CellGuess|CellOpen) != 0 {
-		return
-	}
-	c.State |= CellOpen
-	b.CellsLeft--
-	if c.State&CellMine != 0 {
-		if Game.State == GameReady {
-			b.moveMineToLeftmost(x, y)
-		} else {
-			Game.State = GameDead
-			b.renderAll()
-			return
-		}
-	}
-	if c.Nearby == 0 {
-		b.recursiveOpenCell(x, y)
-	}
-	if b.CellsLeft == 0 {
-		Counter.Set(0)
-		Game.State = GameWin
-	}
-	return
+	b.Pos.Y = (gh - ih) - borderWidth
 }
 
 func (b *Board) tryOpenCell(x, y int) bool {
@@ -202,9 +82,13 @@ func (b *Board) tryOpenCell(x, y int) bool {
 		return false
 	}
 	b.stopXray()
-	b.openCell(x, y)
-	b.renderMask(CellOpen)
-	return true
+	opened := b.practiceMove(func() bool { return b.Open(x, y) })
+	if b.Over() {
+		b.renderAll()
+	} else {
+		b.renderMask(engine.CellOpen)
+	}
+	return opened
 }
 
 func (b *Board) xrayNarrowCell(x, y int) {
@@ -218,40 +102,14 @@ func (b *Board) xrayNarrowCell(x, y int) {
 }
 
 func (b *Board) tryChording(x, y int) bool {
-	st := b.Board[y][x].State
-	if st&CellOpen == 0 {
-		return false
-	}
-	cnt := 0
-	for yy := y - 1; yy <= y+1; yy++ {
-		for xx := x - 1; xx <= x+1; xx++ {
-			if xx < 0 || xx >= b.X || yy < 0 || yy >= b.Y {
-				continue
-			}
-			st := b.Board[yy][xx].State
-			if st&CellFlag != 0 {
-				cnt++
-			}
-		}
-	}
-	if cnt != b.Board[y][x].Nearby {
+	if !b.practiceMove(func() bool { return b.Chord(x, y) }) {
 		return false
 	}
-	for yy := y - 1; yy <= y+1; yy++ {
-		for xx := x - 1; xx <= x+1; xx++ {
-			if xx < 0 || xx >= b.X || yy < 0 || yy >= b.Y {
-				continue
-			} else if xx == x && yy == y {
-				continue
-			}
-			st := b.Board[yy][xx].State
-			if st&(CellFlag|CellOpen) != 0 {
-				continue
-			}
-			b.openCell(xx, yy)
-		}
+	if b.Over() {
+		b.renderAll()
+	} else {
+		b.renderMask(engine.CellOpen | engine.CellFlag | engine.CellGuess)
 	}
-	b.renderMask(CellOpen | CellFlag | CellGuess)
 	return true
 }
 
@@ -262,23 +120,19 @@ func (b *Board) xrayCell(x, y int) {
 	b.renderXray()
 }
 
-func (b *Board) flagCell(x, y int) {
-	cell := &b.Board[y][x]
-	if cell.State&CellOpen != 0 {
-		return
+func (b *Board) flagCell(x, y int) bool {
+	if Input.Chord.Flag && b.Board.Board[y][x].State&engine.CellOpen != 0 {
+		if !b.practiceMove(func() bool { return b.FlagAround(x, y) }) {
+			return false
+		}
+		b.renderMask(engine.CellFlag)
+		return true
 	}
-	switch {
-	case cell.State&CellGuess != 0:
-		cell.State ^= CellGuess
-	case cell.State&CellFlag != 0:
-		cell.State ^= CellFlag
-		cell.State |= CellGuess
-		b.Flags--
-	default:
-		cell.State ^= CellFlag
-		b.Flags++
+	if !b.practiceMove(func() bool { return b.Flag(x, y) }) {
+		return false
 	}
 	b.renderCell(x, y)
+	return true
 }
 
 func (b *Board) disableXray() {
@@ -302,12 +156,24 @@ func (b *Board) cursorCell(ce *CursorEvent) (int, int, bool) {
 func (b *Board) HandleCursorEvent(ce *CursorEvent) (cellChanged, flagChanged bool) {
 	flagChanged = false
 	x, y, ok := b.cursorCell(ce)
+	both := ce.Left&KeyDown != 0 && ce.Right&KeyDown != 0 ||
+		Input.Chord.Middle && ce.Middle&KeyDown != 0
+	pressed := both && !b.chording
+	b.chording = both
 	switch {
 	case !ok:
 		b.stopXray()
-	case ce.Left&KeyDown != 0 && ce.Right&KeyDown != 0:
-		cellChanged = b.tryChording(x, y)
-		if !cellChanged {
+		if pressed {
+			b.chordOK = false
+		}
+	case both:
+		p := image.Point{x, y}
+		if pressed {
+			b.chordAt = p
+			b.chordOK = b.tryChording(x, y)
+			cellChanged = b.chordOK
+		}
+		if !b.chordOK || b.chordAt != p {
 			b.xrayCell(x, y)
 		}
 	case ce.Left&KeyDown != 0 && ce.Right == KeyJust|KeyUp:
@@ -317,17 +183,29 @@ func (b *Board) HandleCursorEvent(ce *CursorEvent) (cellChanged, flagChanged boo
 	case ce.Left&KeyDown != 0:
 		b.xrayNarrowCell(x, y)
 	case ce.Right == KeyJust|KeyDown:
-		b.flagCell(x, y)
-		flagChanged = true
+		flagChanged = b.flagCell(x, y)
+	case ce.Left == KeyJust|KeyUp && ce.Right != KeyJust|KeyUp &&
+		Input.Chord.Left && b.Board.Board[y][x].State&engine.CellOpen != 0:
+		b.stopXray()
+		cellChanged = b.tryChording(x, y)
 	case ce.Left == KeyJust|KeyUp && ce.Right != KeyJust|KeyUp:
 		cellChanged = b.tryOpenCell(x, y)
 	default:
 		b.stopXray()
 	}
+	if cellChanged || flagChanged {
+		b.changed()
+	}
 	return
 }
 
-func openedCellImage(c Cell) *ebiten.Image {
+// changed forgets what was worked out about the board before it changed.
+func (b *Board) changed() {
+	b.hint = nil
+	b.oddsCells, b.oddsErr = nil, nil
+}
+
+func openedCellImage(c engine.Cell) *ebiten.Image {
 	switch c.Nearby {
 	case 0:
 		return Ass.Images.Cell[ImgOpened]
@@ -351,19 +229,19 @@ func openedCellImage(c Cell) *ebiten.Image {
 	return nil
 }
 
-func matchCellImage(c Cell) *ebiten.Image {
+func matchCellImage(c engine.Cell, st engine.GameState) *ebiten.Image {
 	switch {
-	case c.State&CellOpen != 0 && c.State&CellMine != 0:
+	case c.State&engine.CellOpen != 0 && c.State&engine.CellMine != 0:
 		return Ass.Images.Cell[ImgOpenedExploded]
-	case c.State&CellOpen != 0:
+	case c.State&engine.CellOpen != 0:
 		return openedCellImage(c)
-	case c.State&CellGuess != 0:
+	case c.State&engine.CellGuess != 0:
 		return Ass.Images.Cell[ImgGuess]
-	case Game.State == GameDead && c.State&CellFlag != 0 && c.State&CellMine == 0:
+	case st == engine.GameDead && c.State&engine.CellFlag != 0 && c.State&engine.CellMine == 0:
 		return Ass.Images.Cell[ImgWrongFlag]
-	case c.State&CellFlag != 0:
+	case c.State&engine.CellFlag != 0:
 		return Ass.Images.Cell[ImgFlagged]
-	case Game.State == GameDead && c.State&CellOpen == 0 && c.State&CellMine != 0:
+	case st == engine.GameDead && c.State&engine.CellOpen == 0 && c.State&engine.CellMine != 0:
 		return Ass.Images.Cell[ImgOpenedMined]
 	default:
 		return Ass.Images.Cell[ImgUnopened]
@@ -376,7 +254,7 @@ func (b *Board) renderCell(x, y int) {
 	}
 	op := &ebiten.DrawImageOptions{}
 	op.GeoM.Translate(float64(x*16), float64(y*16))
-	b.img.DrawImage(matchCellImage(b.Board[y][x]), op)
+	b.img.DrawImage(matchCellImage(b.Board.Board[y][x], b.State), op)
 }
 
 // Utility function to use while rendering xray
@@ -412,7 +290,7 @@ func (b *Board) renderXray() {
 		return
 	}
 	if b.XrayMode == XrayNarrow {
-		if b.Board[b.XrayY][b.XrayX].State&(CellOpen|CellFlag|CellGuess) != 0 {
+		if b.Board.Board[b.XrayY][b.XrayX].State&(engine.CellOpen|engine.CellFlag|engine.CellGuess) != 0 {
 			return
 		}
 		b.renderOpenCell(b.XrayX, b.XrayY)
@@ -422,7 +300,7 @@ func (b *Board) renderXray() {
 				if xx < 0 || xx >= b.X || yy < 0 || yy >= b.Y {
 					continue
 				}
-				if b.Board[yy][xx].State&(CellOpen|CellFlag|CellGuess) != 0 {
+				if b.Board.Board[yy][xx].State&(engine.CellOpen|engine.CellFlag|engine.CellGuess) != 0 {
 					continue
 				}
 				b.renderOpenCell(xx, yy)
@@ -430,10 +308,10 @@ func (b *Board) renderXray() {
 		}
 	}
 }
-func (b *Board) renderMask(st CellState) {
+func (b *Board) renderMask(st engine.CellState) {
 	for y := 0; y < b.Y; y++ {
 		for x := 0; x < b.X; x++ {
-			if b.Board[y][x].State&st == 0 {
+			if b.Board.Board[y][x].State&st == 0 {
 				continue
 			}
 			b.renderCell(x, y)
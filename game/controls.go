@@ -0,0 +1,203 @@
+package game
+
+import (
+	"log"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+type controlKind int
+
+const (
+	controlKey controlKind = iota
+	controlMouse
+	controlPad
+	controlToggle
+)
+
+// control is a row of the controls screen.
+type control struct {
+	kind  controlKind
+	label string
+	// action is what keys of a controlKey row are bound to.
+	action Action
+	// button is the name bound in a controlMouse or controlPad row.
+	button *string
+	// toggle is the option a controlToggle row switches with Enter.
+	toggle *bool
+}
+
+// ControlsView edits Input. Enter binds a key or button to the row picked
+// with Up and Down or switches its option, backspace clears its keys and
+// Esc saves and closes.
+type ControlsView struct {
+	rows    []control
+	focus   int
+	top     int
+	binding bool
+}
+
+func NewControlsView() *ControlsView {
+	v := &ControlsView{}
+	for _, a := range Actions {
+		v.rows = append(v.rows, control{kind: controlKey, label: string(a), action: a})
+	}
+	m := &Input.Mouse
+	v.rows = append(v.rows,
+		control{kind: controlMouse, label: "mouse open", button: &m.Open},
+		control{kind: controlMouse, label: "mouse flag", button: &m.Flag},
+		control{kind: controlMouse, label: "mouse chord", button: &m.Chord})
+	ch := &Input.Chord
+	v.rows = append(v.rows,
+		control{kind: controlToggle, label: "chord middle", toggle: &ch.Middle},
+		control{kind: controlToggle, label: "chord left", toggle: &ch.Left},
+		control{kind: controlToggle, label: "chord flag", toggle: &ch.Flag})
+	for _, a := range Actions {
+		if b := Input.Gamepad.button(a); b != nil {
+			v.rows = append(v.rows, control{kind: controlPad, label: "pad " + string(a), button: b})
+		}
+	}
+	return v
+}
+
+// height is how many rows fit under the title.
+func (v *ControlsView) height() int {
+	return max(GameBoard.Y*16/lineHeight-1, 1)
+}
+
+func (v *ControlsView) Update() bool {
+	if v.binding {
+		v.bind()
+		return true
+	}
+	c := &v.rows[v.focus]
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		if err := SaveInput(); err != nil {
+			log.Print(err)
+		}
+		return false
+	case repeatDue(inpututil.KeyPressDuration(ebiten.KeyUp)):
+		v.focus = max(v.focus-1, 0)
+	case repeatDue(inpututil.KeyPressDuration(ebiten.KeyDown)):
+		v.focus = min(v.focus+1, len(v.rows)-1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter) && c.kind == controlToggle:
+		*c.toggle = !*c.toggle
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		v.binding = true
+	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace):
+		switch c.kind {
+		case controlKey:
+			Input.Keyboard[c.action] = nil
+		case controlPad:
+			*c.button = ""
+		}
+	}
+	v.top = min(max(v.top, v.focus-v.height()+1), v.focus)
+	return true
+}
+
+// bind waits for the key or button to bind to the focused row. Esc gives
+// up waiting.
+func (v *ControlsView) bind() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		v.binding = false
+		return
+	}
+	c := &v.rows[v.focus]
+	switch c.kind {
+	case controlKey:
+		for _, k := range inpututil.AppendJustPressedKeys(nil) {
+			if k == ebiten.KeyControl || k == ebiten.KeyControlLeft || k == ebiten.KeyControlRight {
+				continue
+			}
+			kb := KeyBinding{Key: k, Ctrl: ebiten.IsKeyPressed(ebiten.KeyControl)}
+			// A key does one thing, so it leaves the action that had it.
+			for a, ks := range Input.Keyboard {
+				Input.Keyboard[a] = slices.DeleteFunc(ks, func(o KeyBinding) bool { return o == kb })
+			}
+			Input.Keyboard[c.action] = append(Input.Keyboard[c.action], kb)
+			v.binding = false
+			return
+		}
+	case controlMouse:
+		for _, name := range sortedNames(mouseButtons) {
+			if inpututil.IsMouseButtonJustPressed(mouseButtons[name]) {
+				v.assign(c, controlMouse, name)
+				return
+			}
+		}
+	case controlPad:
+		for _, name := range sortedNames(gamepadButtons) {
+			if padJustPressed(name) {
+				v.assign(c, controlPad, name)
+				return
+			}
+		}
+	}
+}
+
+// assign binds name to c, swapping it with the row of the same kind that
+// had it so no button does two things. Swapping left and right this way
+// is all it takes for left hands.
+func (v *ControlsView) assign(c *control, kind controlKind, name string) {
+	for _, o := range v.rows {
+		if o.kind == kind && o.button != c.button && *o.button == name {
+			*o.button = *c.button
+		}
+	}
+	*c.button = name
+	v.binding = false
+}
+
+func sortedNames[V any](m map[string]V) []string {
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *control) value() string {
+	if c.kind == controlToggle {
+		if *c.toggle {
+			return "on"
+		}
+		return "off"
+	}
+	if c.kind != controlKey {
+		if *c.button == "" {
+			return "-"
+		}
+		return *c.button
+	}
+	var names []string
+	for _, k := range Input.Keyboard[c.action] {
+		names = append(names, k.String())
+	}
+	if names == nil {
+		return "-"
+	}
+	return strings.Join(names, " ")
+}
+
+func (v *ControlsView) Draw(s *ebiten.Image) {
+	lines := []string{"Controls (Esc)"}
+	for i := v.top; i < min(v.top+v.height(), len(v.rows)); i++ {
+		c := &v.rows[i]
+		l := " " + c.label + " " + c.value()
+		if i == v.focus {
+			l = ">" + l[1:]
+			if v.binding {
+				l = ">" + c.label + " press..."
+			}
+		}
+		lines = append(lines, l)
+	}
+	DrawPanel(s, lines)
+}
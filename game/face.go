@@ -2,6 +2,7 @@ package game
 
 import (
 	"image"
+	"megamine/engine"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
@@ -22,9 +23,9 @@ func (f *FaceObject) Current() *ebiten.Image {
 	switch {
 	case f.Clicked:
 		return Ass.Images.Face[ImgSmilePress]
-	case Game.State == GameDead:
+	case GameBoard.State == engine.GameDead:
 		return Ass.Images.Face[ImgDead]
-	case Game.State == GameWin:
+	case GameBoard.State == engine.GameWin:
 		return Ass.Images.Face[ImgSunglass]
 	case GameBoard.XrayMode != XrayOff && GameBoard.XrayMode != XrayDisabled:
 		return Ass.Images.Face[ImgOops]
@@ -75,7 +76,6 @@ func (f *FaceObject) HandleLeftClickUp(ce *CursorEvent) {
 	}
 	f.Clicked = false
 	if f.underCursor(ce.X, ce.Y) {
-		Game.State = GameReady
 		ResetGame()
 	}
 }
@@ -1,96 +1,352 @@
 package game
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"megamine/engine"
+	"megamine/layout"
+	"megamine/replay"
+	"megamine/store"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 const (
-	GameReady = iota
-	GameActive
-	GameWin
-	GameDead
+	borderWidth = 10
+	topHeight   = 52
 )
 
 type GameObject struct {
 	X, Y    int
-	State   int
+	Scale   float64
 	BeginAt time.Time
+	EndAt   time.Time
+	// Paused games keep the time they were paused at until they resume.
+	Paused   bool
+	PausedAt time.Time
+	// Result holds the stats of the finished game, nil while playing.
+	Result    *engine.Stats
+	NewBest   bool
+	HideStats bool
+	// Analysis shows the chance of a mine in every hidden cell. Games it
+	// is shown in are assisted.
+	Analysis bool
+	// Zoom and pan of the screen, see view.go.
+	Zoom       float64
+	PanX, PanY float64
+	canvas     *ebiten.Image
+	overlay    Overlay
 }
 
 var Game GameObject
 
+// report logs err and shows it, if there is one, so the game goes on
+// rather than quitting over it.
+func (g *GameObject) report(err error) {
+	if err == nil {
+		return
+	}
+	log.Print(err)
+	g.overlay = &Message{Text: err.Error()}
+}
+
 func (g *GameObject) Update() error {
-	ce := GetCursorEvent()
+	if ebiten.IsWindowBeingClosed() {
+		if err := SaveGame(); err != nil {
+			log.Print(err)
+		}
+		return ebiten.Termination
+	}
+	if !ebiten.IsFocused() && !g.Replaying() {
+		g.Pause()
+	}
+	if g.overlay != nil {
+		if !g.overlay.Update() {
+			g.overlay = nil
+		}
+		return nil
+	}
+	if g.Analysis && !GameBoard.Over() && !GameBoard.Assisted {
+		GameBoard.Assisted = true
+		UpdateTitle()
+	}
+	if files := ebiten.DroppedFiles(); files != nil {
+		g.report(OpenDropped(files))
+		return nil
+	}
 	switch {
-	case Game.State == GameWin || Game.State == GameDead:
-		Face.HandleCursorEvent(ce)
-	case Game.State == GameActive:
-		Clock.TrySet(int(time.Now().Sub(Game.BeginAt).Seconds()))
-		Face.HandleCursorEvent(ce)
+	case Input.JustPressed(ActionSave):
+		g.report(SaveGame())
+		return nil
+	case Input.JustPressed(ActionExport):
+		g.report(ExportLayout())
+		return nil
+	case Input.JustPressed(ActionUndo):
+		Undo()
+	case Input.JustPressed(ActionRedo):
+		Redo()
+	case Input.JustPressed(ActionRestart):
+		return ResetGame()
+	case Input.JustPressed(ActionSeed):
+		g.overlay = NewSeedPrompt()
+		return nil
+	case Input.JustPressed(ActionScores):
+		g.overlay = NewScoresView()
+		return nil
+	case Input.JustPressed(ActionNoGuess):
+		return ToggleNoGuess()
+	case Input.JustPressed(ActionNoFlag):
+		return ToggleNoFlag()
+	case Input.JustPressed(ActionFirstClick):
+		return CycleFirstClick()
+	case Input.JustPressed(ActionPractice):
+		TogglePractice()
+	case Input.JustPressed(ActionBeginner):
+		return SetDifficulty(engine.Beginner)
+	case Input.JustPressed(ActionIntermediate):
+		return SetDifficulty(engine.Intermediate)
+	case Input.JustPressed(ActionExpert):
+		return SetDifficulty(engine.Expert)
+	case Input.JustPressed(ActionCustom):
+		g.overlay = NewCustomPrompt()
+		return nil
+	case Input.JustPressed(ActionControls):
+		g.overlay = NewControlsView()
+		return nil
+	case Input.JustPressed(ActionReplay):
+		if LastReplay != nil {
+			return PlayReplay(LastReplay)
+		}
+	case Input.JustPressed(ActionStats):
+		g.HideStats = !g.HideStats
+	case Input.JustPressed(ActionPause):
+		g.TogglePause()
+	case Input.JustPressed(ActionHint) && !g.Paused:
+		Hint()
+	case Input.JustPressed(ActionOdds):
+		g.Analysis = !g.Analysis
+	}
+	if g.Paused {
+		Face.HandleCursorEvent(GetCursorEvent())
+		return nil
+	}
+	ce := GetCursorEvent()
+	now := time.Now()
+	Face.HandleCursorEvent(ce)
+	Recording.Add(ce, now)
+	g.play(ce, now)
+	return nil
+}
+
+// play hands a cursor event to the board as if it happened at now.
+func (g *GameObject) play(ce *CursorEvent, now time.Time) {
+	over := GameBoard.Over()
+	dealt := GameBoard.Dealt()
+	switch GameBoard.State {
+	case engine.GameActive:
+		SetClock(now.Sub(g.BeginAt))
 		_, flagChanged := GameBoard.HandleCursorEvent(ce)
 		if flagChanged {
 			Counter.Set(GameBoard.Mines - GameBoard.Flags)
 		}
-	case Game.State == GameReady:
-		Face.HandleCursorEvent(ce)
-		boardChanged, flagChanged := GameBoard.HandleCursorEvent(ce)
-		if (boardChanged || flagChanged) && Game.State != GameDead {
-			g.State = GameActive
-			Game.BeginAt = time.Now()
+	case engine.GameReady:
+		GameBoard.HandleCursorEvent(ce)
+		if GameBoard.State != engine.GameReady {
+			g.BeginAt = now
 			Counter.Set(GameBoard.Mines - GameBoard.Flags)
 		}
 	}
-	return nil
+	if GameBoard.State == engine.GameWin {
+		Counter.TrySet(0)
+	}
+	if !dealt && GameBoard.Dealt() {
+		UpdateTitle()
+	}
+	if !over && GameBoard.Over() {
+		g.finish(now)
+	}
+}
+
+// finish wraps up the game that just ended at now.
+func (g *GameObject) finish(now time.Time) {
+	g.EndAt = now
+	st := GameBoard.Stats(now.Sub(g.BeginAt))
+	g.Result = &st
+	SetClock(st.Time)
+	g.NewBest = false
+	if g.Replaying() || GameBoard.logged {
+		return
+	}
+	GameBoard.logged = true
+	g.NewBest = recordScore(st)
+	if err := store.AppendHistory(store.Entry{
+		At:         now,
+		Seed:       GameBoard.Seed,
+		X:          GameBoard.X,
+		Y:          GameBoard.Y,
+		Mines:      GameBoard.Mines,
+		Difficulty: engine.DifficultyOf(GameBoard.X, GameBoard.Y, GameBoard.Mines).String(),
+		Rules:      GameBoard.Rules,
+		Stats:      st,
+		Ranked:     ranked(),
+	}); err != nil {
+		log.Print(err)
+	}
+	if err := Recording.Finish(); err != nil {
+		log.Print(err)
+	}
 }
 
 func (g *GameObject) Draw(screen *ebiten.Image) {
-	DrawScreen(screen)
+	g.drawZoomed(screen, func(s *ebiten.Image) {
+		DrawScreen(s)
+		DrawAnalysis(s)
+		DrawHint(s)
+		DrawCursor(s)
+		DrawPaused(s)
+		DrawStats(s)
+		if g.overlay != nil {
+			g.overlay.Draw(s)
+		}
+	})
 }
 
 func (g *GameObject) Layout(outw, outh int) (w, h int) {
 	return g.X, g.Y
 }
 
-func InitGame() error {
+// Resize fits the screen and window around the board.
+func (g *GameObject) Resize() {
+	g.X = max(16*GameBoard.X+borderWidth*2, headerWidth())
+	g.Y = 16*GameBoard.Y + topHeight + borderWidth
+	g.Zoom, g.PanX, g.PanY = 1, 0, 0
+	ebiten.SetWindowSize(int(float64(g.X)*g.Scale), int(float64(g.Y)*g.Scale))
+}
+
+// Options only matter when the game starts. Everything else lives in
+// Settings.
+type Options struct {
+	Seed  int64
+	Scale float64
+	// Resume continues the saved game instead, if there is one.
+	Resume bool
+	// Replay is a recording to play back instead of a game.
+	Replay string
+	// Board is a layout to play on instead of random boards.
+	Board string
+}
+
+func InitGame(opts Options) error {
 
 	err := ImportGameImages()
 	if err != nil {
 		return err
 	}
-	ebiten.SetWindowSize(756, 482)
-	ebiten.SetWindowTitle("MegaMine!")
 	ebiten.SetWindowResizable(true)
+	ebiten.SetWindowClosingHandled(true)
+	// Keep updating without focus to notice it is gone and pause.
+	ebiten.SetRunnableOnUnfocused(true)
 
 	Game = GameObject{
-		X:     500 * 1,
-		Y:     318 * 1,
-		State: GameReady,
+		Scale: opts.Scale,
 	}
-	err = InitBoard()
+	err = InitBoard(opts.Seed)
 	if err != nil {
 		return err
 	}
 	InitSegDisp()
+	Game.Resize()
+	LoadHighScores()
 	Clock.Set(0)
 	Counter.Set(GameBoard.Mines)
 	UpdatePos()
+	UpdateTitle()
+	Recording.Start()
+	if opts.Replay != "" {
+		rep, err := replay.Load(opts.Replay)
+		if err != nil {
+			return err
+		}
+		return PlayReplay(rep)
+	}
+	if opts.Board != "" {
+		b, err := layout.Load(opts.Board)
+		if err != nil {
+			return err
+		}
+		return PlayLayout(b)
+	}
+	if opts.Resume {
+		err := ResumeGame()
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Print(err)
+		}
+	}
 	return nil
 }
 
 func ResetGame() error {
-	board, err := NewBoard(30*1, 16*1, 99*1)
+	if Drill != nil {
+		return resetDrill()
+	}
+	return ResetGameSeed(engine.NewSeed())
+}
+
+// ResetGameSeed starts a new game on the board generated from seed.
+func ResetGameSeed(seed int64) error {
+	d := Settings.Difficulty
+	board, err := NewBoard(d.X, d.Y, d.Mines, seed)
 	if err != nil {
 		return err
 	}
-	board.Generate()
+	Drill = nil
+	SetBoard(board)
+	Recording.Start()
+	return nil
+}
+
+// SetBoard puts a fresh board in play.
+func SetBoard(board *Board) {
+	resize := GameBoard == nil || board.X != GameBoard.X || board.Y != GameBoard.Y
+	board.Practice = board.Practice || Settings.Practice
 	GameBoard = board
-	Game.State = GameReady
+	Game.Result = nil
+	Game.Paused = false
+	if resize {
+		Game.Resize()
+	}
 	UpdatePos()
+	UpdateTitle()
 	Clock.Set(0)
 	Counter.Set(GameBoard.Mines)
-	return nil
+}
+
+func UpdateTitle() {
+	mode := " " + GameBoard.Rules.FirstClick.String()
+	if GameBoard.Rules.NoGuess {
+		mode = " NG"
+		if GameBoard.NoGuessFailed() {
+			mode = " NG failed, may need a guess"
+		}
+	}
+	if GameBoard.Rules.NoFlag {
+		mode += " NF"
+	}
+	if GameBoard.Practice {
+		mode += " practice"
+	}
+	if GameBoard.Assisted {
+		mode += " assisted"
+	}
+	d := engine.DifficultyOf(GameBoard.X, GameBoard.Y, GameBoard.Mines)
+	if GameBoard.Fixed {
+		ebiten.SetWindowTitle(fmt.Sprintf("MegaMine! %s%s layout", d, mode))
+		return
+	}
+	ebiten.SetWindowTitle(fmt.Sprintf("MegaMine! %s%s #%d", d, mode, GameBoard.Seed))
 }
 
 func UpdatePos() {
@@ -0,0 +1,106 @@
+package game
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// gamepadButtons names the buttons of a standard gamepad after the common
+// layout with A at the bottom.
+var gamepadButtons = map[string]ebiten.StandardGamepadButton{
+	"a":     ebiten.StandardGamepadButtonRightBottom,
+	"b":     ebiten.StandardGamepadButtonRightRight,
+	"x":     ebiten.StandardGamepadButtonRightLeft,
+	"y":     ebiten.StandardGamepadButtonRightTop,
+	"lb":    ebiten.StandardGamepadButtonFrontTopLeft,
+	"rb":    ebiten.StandardGamepadButtonFrontTopRight,
+	"lt":    ebiten.StandardGamepadButtonFrontBottomLeft,
+	"rt":    ebiten.StandardGamepadButtonFrontBottomRight,
+	"back":  ebiten.StandardGamepadButtonCenterLeft,
+	"start": ebiten.StandardGamepadButtonCenterRight,
+	"ls":    ebiten.StandardGamepadButtonLeftStick,
+	"rs":    ebiten.StandardGamepadButtonRightStick,
+}
+
+// stickThreshold is how far the stick must lean to move the cursor.
+const stickThreshold = 0.5
+
+// stickFrames counts the frames the stick has been leaning, to repeat
+// moves like a held key.
+var stickFrames int
+
+func gamepads() []ebiten.GamepadID {
+	var ids []ebiten.GamepadID
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func padPressed(name string) bool {
+	for _, id := range gamepads() {
+		if ebiten.IsStandardGamepadButtonPressed(id, gamepadButtons[name]) {
+			return true
+		}
+	}
+	return false
+}
+
+func padJustPressed(name string) bool {
+	for _, id := range gamepads() {
+		if inpututil.IsStandardGamepadButtonJustPressed(id, gamepadButtons[name]) {
+			return true
+		}
+	}
+	return false
+}
+
+func repeatDue(frames int) bool {
+	return frames == 1 || (frames > keyRepeatDelay && (frames-keyRepeatDelay)%keyRepeatRate == 0)
+}
+
+// padMove returns where the D-pads and left sticks move the cursor this
+// frame.
+func padMove() (dx, dy int) {
+	sx, sy := 0.0, 0.0
+	for _, id := range gamepads() {
+		for _, d := range []struct {
+			button ebiten.StandardGamepadButton
+			dx, dy int
+		}{
+			{ebiten.StandardGamepadButtonLeftLeft, -1, 0},
+			{ebiten.StandardGamepadButtonLeftRight, 1, 0},
+			{ebiten.StandardGamepadButtonLeftTop, 0, -1},
+			{ebiten.StandardGamepadButtonLeftBottom, 0, 1},
+		} {
+			if repeatDue(inpututil.StandardGamepadButtonPressDuration(id, d.button)) {
+				dx, dy = d.dx, d.dy
+			}
+		}
+		sx += ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+		sy += ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickVertical)
+	}
+	if (sx > -stickThreshold && sx < stickThreshold) && (sy > -stickThreshold && sy < stickThreshold) {
+		stickFrames = 0
+		return
+	}
+	stickFrames++
+	if !repeatDue(stickFrames) {
+		return
+	}
+	switch {
+	case sx <= -stickThreshold:
+		dx = -1
+	case sx >= stickThreshold:
+		dx = 1
+	}
+	switch {
+	case sy <= -stickThreshold:
+		dy = -1
+	case sy >= stickThreshold:
+		dy = 1
+	}
+	return
+}
@@ -0,0 +1,96 @@
+package game
+
+import (
+	"image/color"
+	"megamine/engine"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+var (
+	hintSafeColor   = color.RGBA{G: 0xc0, A: 0xff}
+	hintMineColor   = color.RGBA{R: 0xff, A: 0xff}
+	hintReasonColor = color.RGBA{R: 0xff, G: 0xd0, A: 0xff}
+)
+
+// Hint shows a cell that follows from what is on the board and the
+// numbers it follows from. When no simple rule gives one, every case is
+// counted instead. Asking marks the game as assisted.
+func Hint() {
+	b := GameBoard
+	if b.Over() {
+		return
+	}
+	b.Assisted = true
+	UpdateTitle()
+	for _, d := range engine.Deduce(b.View()) {
+		// A flag already on a mine is no news.
+		if d.Mine && b.Board.Board[d.Y][d.X].State&engine.CellFlag != 0 {
+			continue
+		}
+		b.hint = &d
+		b.hintText = "It is " + hintWhat(d.Mine) + " by the yellow numbers"
+		if len(d.Reason) == 0 {
+			b.hintText = "It is " + hintWhat(d.Mine) + " by the mine count"
+		}
+		return
+	}
+	b.hint = &engine.Deduction{X: -1, Y: -1}
+	certain, err := engine.Certain(b.View())
+	if err != nil {
+		b.hintText = "Nothing simple follows"
+		return
+	}
+	b.hintText = "Nothing follows, guess"
+	// Safe cells are the more use; a flag already on a mine is no news.
+	var pick *engine.Deduction
+	for i, d := range certain {
+		if !d.Mine {
+			pick = &certain[i]
+			break
+		} else if pick == nil && b.Board.Board[d.Y][d.X].State&engine.CellFlag == 0 {
+			pick = &certain[i]
+		}
+	}
+	if pick == nil {
+		return
+	}
+	b.hint = pick
+	b.hintText = "It is " + hintWhat(pick.Mine) + " in every case by the yellow numbers"
+	if len(pick.Reason) == 0 {
+		b.hintText = "It is " + hintWhat(pick.Mine) + " in every case"
+	}
+}
+
+func hintWhat(mine bool) string {
+	if mine {
+		return "a mine"
+	}
+	return "safe"
+}
+
+func DrawHint(s *ebiten.Image) {
+	b := GameBoard
+	d := b.hint
+	if d == nil {
+		return
+	}
+	for _, p := range d.Reason {
+		vector.StrokeRect(s, float32(b.Pos.X+p.X*16), float32(b.Pos.Y+p.Y*16),
+			16, 16, 1, hintReasonColor, false)
+	}
+	if d.X >= 0 {
+		c := hintSafeColor
+		if d.Mine {
+			c = hintMineColor
+		}
+		vector.StrokeRect(s, float32(b.Pos.X+d.X*16), float32(b.Pos.Y+d.Y*16),
+			16, 16, 2, c, false)
+	}
+	y := b.Pos.Y + b.Y*16 - lineHeight
+	vector.DrawFilledRect(s, float32(b.Pos.X), float32(y),
+		float32(b.X*16), lineHeight, replayBarColor, false)
+	ebitenutil.DebugPrintAt(s, b.hintText, b.Pos.X+2, y)
+}
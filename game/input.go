@@ -0,0 +1,356 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"megamine/store"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const inputName = "input.json"
+
+// Action is something a control can be bound to.
+type Action string
+
+const (
+	ActionLeft         Action = "left"
+	ActionRight        Action = "right"
+	ActionUp           Action = "up"
+	ActionDown         Action = "down"
+	ActionOpen         Action = "open"
+	ActionFlag         Action = "flag"
+	ActionChord        Action = "chord"
+	ActionRestart      Action = "restart"
+	ActionPause        Action = "pause"
+	ActionHint         Action = "hint"
+	ActionOdds         Action = "odds"
+	ActionUndo         Action = "undo"
+	ActionRedo         Action = "redo"
+	ActionStats        Action = "stats"
+	ActionScores       Action = "scores"
+	ActionSave         Action = "save"
+	ActionExport       Action = "export"
+	ActionSeed         Action = "seed"
+	ActionReplay       Action = "replay"
+	ActionNoGuess      Action = "noguess"
+	ActionNoFlag       Action = "noflag"
+	ActionFirstClick   Action = "firstclick"
+	ActionPractice     Action = "practice"
+	ActionBeginner     Action = "beginner"
+	ActionIntermediate Action = "intermediate"
+	ActionExpert       Action = "expert"
+	ActionCustom       Action = "custom"
+	ActionControls     Action = "controls"
+)
+
+// Actions lists every action in the order the controls screen shows them.
+var Actions = []Action{
+	ActionLeft, ActionRight, ActionUp, ActionDown, ActionOpen, ActionFlag, ActionChord,
+	ActionRestart, ActionPause, ActionHint, ActionOdds, ActionUndo, ActionRedo,
+	ActionStats, ActionScores, ActionSave, ActionExport, ActionSeed, ActionReplay,
+	ActionNoGuess, ActionNoFlag, ActionFirstClick, ActionPractice,
+	ActionBeginner, ActionIntermediate, ActionExpert, ActionCustom, ActionControls,
+}
+
+// KeyBinding is a key, with or without control held. It is written as
+// the key's name, such as "F2" or "Ctrl+Z".
+type KeyBinding struct {
+	Key  ebiten.Key
+	Ctrl bool
+}
+
+const ctrlPrefix = "Ctrl+"
+
+func (k KeyBinding) String() string {
+	if k.Ctrl {
+		return ctrlPrefix + k.Key.String()
+	}
+	return k.Key.String()
+}
+
+func (k KeyBinding) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+func (k *KeyBinding) UnmarshalText(text []byte) error {
+	s := string(text)
+	k.Ctrl = len(s) > len(ctrlPrefix) && strings.EqualFold(s[:len(ctrlPrefix)], ctrlPrefix)
+	if k.Ctrl {
+		s = s[len(ctrlPrefix):]
+	}
+	return k.Key.UnmarshalText([]byte(s))
+}
+
+// held reports whether the key is down with control as the binding
+// wants it. Keys without control do nothing while it is held.
+func (k KeyBinding) held() bool {
+	return ebiten.IsKeyPressed(k.Key) && ebiten.IsKeyPressed(ebiten.KeyControl) == k.Ctrl
+}
+
+func (k KeyBinding) justPressed() bool {
+	return inpututil.IsKeyJustPressed(k.Key) && ebiten.IsKeyPressed(ebiten.KeyControl) == k.Ctrl
+}
+
+// InputMap binds controls to actions. It is read from the config directory
+// so it can be edited by hand.
+type InputMap struct {
+	Keyboard map[Action][]KeyBinding
+	Mouse    MouseMap
+	Gamepad  GamepadMap
+	Touch    TouchMap
+	Chord    ChordSettings
+}
+
+// MouseMap binds the buttons of the mouse by the names in mouseButtons.
+// Chord stands in for the middle button.
+type MouseMap struct {
+	Open, Flag, Chord string
+}
+
+var mouseButtons = map[string]ebiten.MouseButton{
+	"left":    ebiten.MouseButtonLeft,
+	"right":   ebiten.MouseButtonRight,
+	"middle":  ebiten.MouseButtonMiddle,
+	"back":    ebiten.MouseButton3,
+	"forward": ebiten.MouseButton4,
+}
+
+// GamepadMap binds buttons of a standard gamepad, by the names in
+// gamepadButtons.
+type GamepadMap struct {
+	Open, Flag, Chord, Restart, Pause, Hint string
+	// Mappings are lines of SDL's game controller database, for gamepads
+	// that are not known to have a standard layout.
+	Mappings string `json:",omitempty"`
+}
+
+// button returns where the button bound to a is kept, or nil for actions
+// gamepads cannot be bound to.
+func (m *GamepadMap) button(a Action) *string {
+	switch a {
+	case ActionOpen:
+		return &m.Open
+	case ActionFlag:
+		return &m.Flag
+	case ActionChord:
+		return &m.Chord
+	case ActionRestart:
+		return &m.Restart
+	case ActionPause:
+		return &m.Pause
+	case ActionHint:
+		return &m.Hint
+	}
+	return nil
+}
+
+// ChordSettings are the ways to chord besides both buttons at once. They
+// convert to replay.Chording.
+type ChordSettings struct {
+	// Middle chords with the middle button.
+	Middle bool
+	// Left chords with a left click on an opened number.
+	Left bool
+	// Flag flags the neighbours of a number with a right click when they
+	// can only be mines.
+	Flag bool
+}
+
+type TouchMap struct {
+	// LongPressMs is how long a finger is held to flag.
+	LongPressMs int
+}
+
+var Input = DefaultInput()
+
+func keys(names ...string) []KeyBinding {
+	var ks []KeyBinding
+	for _, n := range names {
+		var k KeyBinding
+		if err := k.UnmarshalText([]byte(n)); err != nil {
+			panic(err)
+		}
+		ks = append(ks, k)
+	}
+	return ks
+}
+
+func DefaultInput() InputMap {
+	return InputMap{
+		Keyboard: map[Action][]KeyBinding{
+			ActionLeft:         keys("ArrowLeft", "H", "A"),
+			ActionRight:        keys("ArrowRight", "L", "D"),
+			ActionUp:           keys("ArrowUp", "K", "W"),
+			ActionDown:         keys("ArrowDown", "J", "S"),
+			ActionOpen:         keys("Space"),
+			ActionFlag:         keys("F"),
+			ActionChord:        keys("C"),
+			ActionRestart:      keys("N", "F2"),
+			ActionPause:        keys("P"),
+			ActionHint:         keys("I"),
+			ActionOdds:         keys("O"),
+			ActionUndo:         keys("Ctrl+Z"),
+			ActionRedo:         keys("Ctrl+Y"),
+			ActionStats:        keys("Tab"),
+			ActionScores:       keys("F4"),
+			ActionSave:         keys("Ctrl+S"),
+			ActionExport:       keys("Ctrl+E"),
+			ActionSeed:         keys("F3"),
+			ActionReplay:       keys("F8"),
+			ActionNoGuess:      keys("F5"),
+			ActionNoFlag:       keys("F10"),
+			ActionFirstClick:   keys("F6"),
+			ActionPractice:     keys("F7"),
+			ActionBeginner:     keys("1"),
+			ActionIntermediate: keys("2"),
+			ActionExpert:       keys("3"),
+			ActionCustom:       keys("4"),
+			ActionControls:     keys("F9"),
+		},
+		Mouse: MouseMap{
+			Open:  "left",
+			Flag:  "right",
+			Chord: "middle",
+		},
+		Gamepad: GamepadMap{
+			Open:    "a",
+			Flag:    "x",
+			Chord:   "b",
+			Restart: "start",
+			Pause:   "back",
+			Hint:    "y",
+		},
+		Touch: TouchMap{
+			LongPressMs: 400,
+		},
+		Chord: ChordSettings{
+			Middle: true,
+		},
+	}
+}
+
+// check makes sure every name is known and no key or button is bound
+// twice, which would do both things at once.
+func (m *InputMap) check() error {
+	bound := map[KeyBinding]Action{}
+	for a, ks := range m.Keyboard {
+		for _, k := range ks {
+			if other, ok := bound[k]; ok && other != a {
+				return errors.New(fmt.Sprintf("key %s is bound to both %s and %s", k, other, a))
+			}
+			bound[k] = a
+		}
+	}
+	mouse := map[string]bool{}
+	for _, name := range []string{m.Mouse.Open, m.Mouse.Flag, m.Mouse.Chord} {
+		if _, ok := mouseButtons[name]; !ok {
+			return errors.New(fmt.Sprintf("unknown mouse button: %s", name))
+		}
+		if mouse[name] {
+			return errors.New(fmt.Sprintf("mouse button %s is bound twice", name))
+		}
+		mouse[name] = true
+	}
+	pad := map[string]bool{}
+	for _, a := range Actions {
+		b := m.Gamepad.button(a)
+		if b == nil || *b == "" {
+			continue
+		}
+		if _, ok := gamepadButtons[*b]; !ok {
+			return errors.New(fmt.Sprintf("unknown gamepad button: %s", *b))
+		}
+		if pad[*b] {
+			return errors.New(fmt.Sprintf("gamepad button %s is bound twice", *b))
+		}
+		pad[*b] = true
+	}
+	if m.Touch.LongPressMs <= 0 {
+		return errors.New("long press must last a while")
+	}
+	return nil
+}
+
+// KeyNames names the keys bound to a, such as "N/F2", for labels that say
+// which key to press. It is empty if a has no key.
+func (m *InputMap) KeyNames(a Action) string {
+	var names []string
+	for _, k := range m.Keyboard[a] {
+		names = append(names, k.String())
+	}
+	return strings.Join(names, "/")
+}
+
+// Held reports whether a key or gamepad button bound to a is down.
+func (m *InputMap) Held(a Action) bool {
+	for _, k := range m.Keyboard[a] {
+		if k.held() {
+			return true
+		}
+	}
+	if b := m.Gamepad.button(a); b != nil && *b != "" {
+		return padPressed(*b)
+	}
+	return false
+}
+
+// JustPressed reports whether a key or gamepad button bound to a went
+// down this frame.
+func (m *InputMap) JustPressed(a Action) bool {
+	for _, k := range m.Keyboard[a] {
+		if k.justPressed() {
+			return true
+		}
+	}
+	if b := m.Gamepad.button(a); b != nil && *b != "" {
+		return padJustPressed(*b)
+	}
+	return false
+}
+
+// Repeated reports whether a key bound to a is down and due to act this
+// frame, as if it repeated.
+func (m *InputMap) Repeated(a Action) bool {
+	for _, k := range m.Keyboard[a] {
+		if ebiten.IsKeyPressed(ebiten.KeyControl) == k.Ctrl && repeatDue(inpututil.KeyPressDuration(k.Key)) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadInput reads the input map, writing the default one first if there
+// is none. The default stays in use if the file is broken. Actions the
+// file leaves out keep their default keys.
+func LoadInput() {
+	m := DefaultInput()
+	err := store.ReadJSON(inputName, &m)
+	if errors.Is(err, fs.ErrNotExist) {
+		err = store.WriteJSON(inputName, &m)
+	}
+	if err == nil && m.Keyboard == nil {
+		m.Keyboard = DefaultInput().Keyboard
+	}
+	if err == nil {
+		err = m.check()
+	}
+	if err != nil {
+		log.Print(err)
+		return
+	}
+	if m.Gamepad.Mappings != "" {
+		if _, err := ebiten.UpdateStandardGamepadLayoutMappings(m.Gamepad.Mappings); err != nil {
+			log.Print(err)
+		}
+	}
+	Input = m
+}
+
+func SaveInput() error {
+	return store.WriteJSON(inputName, &Input)
+}
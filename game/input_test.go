@@ -0,0 +1,83 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestKeyBindingText(t *testing.T) {
+	tests := []struct {
+		text string
+		want KeyBinding
+		// out is the text written back, when it differs.
+		out string
+	}{
+		{"F2", KeyBinding{Key: ebiten.KeyF2}, ""},
+		{"Ctrl+Z", KeyBinding{Key: ebiten.KeyZ, Ctrl: true}, ""},
+		{"ctrl+z", KeyBinding{Key: ebiten.KeyZ, Ctrl: true}, "Ctrl+Z"},
+		{"Digit1", KeyBinding{Key: ebiten.KeyDigit1}, ""},
+		{"1", KeyBinding{Key: ebiten.KeyDigit1}, "Digit1"},
+		{"ArrowLeft", KeyBinding{Key: ebiten.KeyArrowLeft}, ""},
+	}
+	for _, tt := range tests {
+		var k KeyBinding
+		if err := k.UnmarshalText([]byte(tt.text)); err != nil {
+			t.Errorf("%q: %v", tt.text, err)
+			continue
+		}
+		if k != tt.want {
+			t.Errorf("%q read as %+v, want %+v", tt.text, k, tt.want)
+		}
+		out := tt.out
+		if out == "" {
+			out = tt.text
+		}
+		if text, _ := k.MarshalText(); string(text) != out {
+			t.Errorf("%q written as %q, want %q", tt.text, text, out)
+		}
+	}
+	for _, bad := range []string{"", "Ctrl+", "Hyper", "Ctrl+Hyper"} {
+		var k KeyBinding
+		if err := k.UnmarshalText([]byte(bad)); err == nil {
+			t.Errorf("%q read as %+v", bad, k)
+		}
+	}
+}
+
+func TestInputCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(m *InputMap)
+		ok     bool
+	}{
+		{"default", func(m *InputMap) {}, true},
+		{"key twice", func(m *InputMap) { m.Keyboard[ActionHint] = keys("P") }, false},
+		{"key twice in one action", func(m *InputMap) { m.Keyboard[ActionHint] = keys("I", "I") }, true},
+		{"ctrl apart", func(m *InputMap) { m.Keyboard[ActionHint] = keys("Ctrl+P") }, true},
+		{"mouse twice", func(m *InputMap) { m.Mouse.Chord = "left" }, false},
+		{"unknown mouse", func(m *InputMap) { m.Mouse.Chord = "wheel" }, false},
+		{"gamepad twice", func(m *InputMap) { m.Gamepad.Hint = "a" }, false},
+		{"gamepad unbound", func(m *InputMap) { m.Gamepad.Hint, m.Gamepad.Pause = "", "" }, true},
+		{"unknown gamepad", func(m *InputMap) { m.Gamepad.Hint = "turbo" }, false},
+		{"no long press", func(m *InputMap) { m.Touch.LongPressMs = 0 }, false},
+	}
+	for _, tt := range tests {
+		m := DefaultInput()
+		tt.change(&m)
+		if err := m.check(); (err == nil) != tt.ok {
+			t.Errorf("%s: error %v", tt.name, err)
+		}
+	}
+}
+
+func TestKeyNames(t *testing.T) {
+	m := DefaultInput()
+	if got := m.KeyNames(ActionRestart); got != "N/F2" {
+		t.Errorf("restart keys %q", got)
+	}
+	m.Keyboard[ActionPause] = nil
+	if got := m.KeyNames(ActionPause); got != "" {
+		t.Errorf("no pause keys named %q", got)
+	}
+}
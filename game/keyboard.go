@@ -0,0 +1,110 @@
+package game
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	// Held movement keys repeat after keyRepeatDelay frames, every
+	// keyRepeatRate frames.
+	keyRepeatDelay = 15
+	keyRepeatRate  = 3
+)
+
+var cursorColor = color.RGBA{
+	R: 0xff,
+	G: 0xd0,
+	A: 0xff,
+}
+
+// KeyCursor is a cell picked with the keyboard or a gamepad. It stands in
+// for the mouse from the first key or button until the mouse is used
+// again.
+type KeyCursor struct {
+	X, Y   int
+	Active bool
+	// The mouse as last seen, to notice it being used.
+	mouseX, mouseY int
+	// The buttons the keys held down last frame.
+	left, right bool
+}
+
+var Cursor KeyCursor
+
+func buttonState(prev, cur bool) KeyState {
+	switch {
+	case cur && !prev:
+		return KeyJust | KeyDown
+	case cur:
+		return KeyDown
+	case prev:
+		return KeyJust | KeyUp
+	default:
+		return KeyUp
+	}
+}
+
+// Event returns the cursor event of the keyboard and gamepads, or nil
+// while the mouse is in use. mouse is the event of the mouse this frame.
+func (c *KeyCursor) Event(mouse *CursorEvent) *CursorEvent {
+	if mouse.X != c.mouseX || mouse.Y != c.mouseY ||
+		mouse.Left&KeyDown != 0 || mouse.Right&KeyDown != 0 {
+		c.Active = false
+	}
+	c.mouseX, c.mouseY = mouse.X, mouse.Y
+
+	dx, dy := 0, 0
+	switch {
+	case Input.Repeated(ActionLeft):
+		dx = -1
+	case Input.Repeated(ActionRight):
+		dx = 1
+	case Input.Repeated(ActionUp):
+		dy = -1
+	case Input.Repeated(ActionDown):
+		dy = 1
+	default:
+		dx, dy = padMove()
+	}
+	chord := Input.Held(ActionChord)
+	left := Input.Held(ActionOpen) || chord
+	right := Input.Held(ActionFlag) || chord
+	if dx != 0 || dy != 0 || left || right {
+		if !c.Active {
+			// Pick up where the mouse is, if it is on the board.
+			if x, y, ok := GameBoard.cursorCell(mouse); ok {
+				c.X, c.Y = x, y
+				dx, dy = 0, 0
+			}
+			c.Active = true
+		}
+		c.X = min(max(c.X+dx, 0), GameBoard.X-1)
+		c.Y = min(max(c.Y+dy, 0), GameBoard.Y-1)
+	}
+	if !c.Active {
+		c.left, c.right = false, false
+		return nil
+	}
+	ce := &CursorEvent{
+		X:      GameBoard.Pos.X + c.X*16 + 8,
+		Y:      GameBoard.Pos.Y + c.Y*16 + 8,
+		Left:   buttonState(c.left, left),
+		Middle: KeyUp,
+		Right:  buttonState(c.right, right),
+	}
+	c.left, c.right = left, right
+	return ce
+}
+
+// DrawCursor outlines the cell of the keyboard cursor.
+func DrawCursor(s *ebiten.Image) {
+	if !Cursor.Active || Game.Paused {
+		return
+	}
+	b := GameBoard
+	vector.StrokeRect(s, float32(b.Pos.X+Cursor.X*16), float32(b.Pos.Y+Cursor.Y*16),
+		16, 16, 2, cursorColor, false)
+}
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"megamine/engine"
+	"megamine/layout"
+	"megamine/replay"
+	"megamine/store"
+	"time"
+)
+
+// Drill is the board loaded by the player. While it is set, new games are
+// played on its mines instead of on random boards.
+var Drill *engine.Board
+
+func PlayLayout(b *engine.Board) error {
+	Drill = b
+	return ResetGame()
+}
+
+func resetDrill() error {
+	eb, err := engine.NewBoardLayout(Drill.X, Drill.Y, Drill.Layout())
+	if err != nil {
+		return err
+	}
+	eb.Rules = Settings.Rules
+	SetBoard(newBoard(eb))
+	Recording.Start()
+	return nil
+}
+
+// ExportLayout writes where the mines of the current board are, both as
+// MBF and as text. Random boards are only written once the game is over:
+// the first click may still move mines before, and telling where they are
+// during the game would give it away.
+func ExportLayout() error {
+	if !GameBoard.Fixed && !GameBoard.Over() {
+		return errors.New("the board can be exported once the game is over")
+	}
+	name := "boards/" + time.Now().Format("20060102-150405")
+	if !GameBoard.Fixed {
+		name += fmt.Sprintf("-%d", GameBoard.Seed)
+	}
+	for _, ext := range []string{".mbf", ".txt"} {
+		p, err := store.Path(name + ext)
+		if err != nil {
+			return err
+		}
+		if err := layout.Save(p, GameBoard.Board); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OpenDropped plays the first file dropped on the window, a replay or a
+// board.
+func OpenDropped(files fs.FS) error {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+	name := entries[0].Name()
+	f, err := files.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	switch replay.Format(name) {
+	case ".rmv", ".avf", ".json":
+		rep, err := replay.Read(name, f)
+		if err != nil {
+			return err
+		}
+		return PlayReplay(rep)
+	}
+	b, err := layout.Read(name, f)
+	if err != nil {
+		return err
+	}
+	return PlayLayout(b)
+}
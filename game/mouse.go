@@ -18,28 +18,39 @@ type CursorEvent struct {
 	Left, Middle, Right KeyState
 }
 
+// GetCursorEvent returns the event of touches or the keyboard cursor
+// while they are in use and that of the mouse otherwise.
 func GetCursorEvent() *CursorEvent {
-	ce := &CursorEvent{}
-	ce.X, ce.Y = ebiten.CursorPosition()
-	ce.Middle = KeyUp
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-		ce.Left = (KeyJust | KeyDown)
-	} else if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
-		ce.Left = KeyDown
-	} else if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) {
-		ce.Left = (KeyJust | KeyUp)
-	} else {
-		ce.Left = KeyUp
+	if tce := Touch.Event(); tce != nil {
+		return tce
 	}
-
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
-		ce.Right = (KeyJust | KeyDown)
-	} else if ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight) {
-		ce.Right = KeyDown
-	} else if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonRight) {
-		ce.Right = (KeyJust | KeyUp)
-	} else {
-		ce.Right = KeyUp
+	ce := mouseEvent()
+	if kce := Cursor.Event(ce); kce != nil {
+		return kce
 	}
 	return ce
-}
\ No newline at end of file
+}
+
+// mouseEvent reads the mouse, with its buttons as bound in Input.Mouse.
+func mouseEvent() *CursorEvent {
+	ce := &CursorEvent{}
+	ce.X, ce.Y = toCanvas(ebiten.CursorPosition())
+	m := Input.Mouse
+	ce.Left = mouseState(mouseButtons[m.Open])
+	ce.Middle = mouseState(mouseButtons[m.Chord])
+	ce.Right = mouseState(mouseButtons[m.Flag])
+	return ce
+}
+
+func mouseState(b ebiten.MouseButton) KeyState {
+	switch {
+	case inpututil.IsMouseButtonJustPressed(b):
+		return KeyJust | KeyDown
+	case ebiten.IsMouseButtonPressed(b):
+		return KeyDown
+	case inpututil.IsMouseButtonJustReleased(b):
+		return KeyJust | KeyUp
+	default:
+		return KeyUp
+	}
+}
@@ -0,0 +1,63 @@
+package game
+
+import (
+	"megamine/engine"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Pause stops the clock of the game in progress and hides the board.
+func (g *GameObject) Pause() {
+	if g.Paused || GameBoard.State != engine.GameActive {
+		return
+	}
+	g.Paused = true
+	g.PausedAt = time.Now()
+	GameBoard.stopXray()
+}
+
+// Resume continues the paused game as if the pause never happened.
+func (g *GameObject) Resume() {
+	if !g.Paused {
+		return
+	}
+	d := time.Now().Sub(g.PausedAt)
+	g.BeginAt = g.BeginAt.Add(d)
+	Recording.Shift(d)
+	g.Paused = false
+}
+
+func (g *GameObject) TogglePause() {
+	if g.Paused {
+		g.Resume()
+	} else {
+		g.Pause()
+	}
+}
+
+// Elapsed is how long the game in progress has been played at now,
+// pauses left out.
+func (g *GameObject) Elapsed(now time.Time) time.Duration {
+	if g.Paused {
+		now = g.PausedAt
+	}
+	return now.Sub(g.BeginAt)
+}
+
+// DrawPaused covers the board so it cannot be studied during a pause.
+func DrawPaused(s *ebiten.Image) {
+	if !Game.Paused {
+		return
+	}
+	b := GameBoard
+	vector.DrawFilledRect(s, float32(b.Pos.X), float32(b.Pos.Y),
+		float32(b.X*16), float32(b.Y*16), bgColor, false)
+	text := "Paused"
+	if k := Input.KeyNames(ActionPause); k != "" {
+		text += ", " + k + " resumes"
+	}
+	ebitenutil.DebugPrintAt(s, text, b.Pos.X+4, b.Pos.Y+4)
+}
@@ -0,0 +1,70 @@
+package game
+
+import (
+	"megamine/engine"
+	"time"
+)
+
+// practiceMove makes a move and, in practice, remembers the board as it
+// was before if the move changed it.
+func (b *Board) practiceMove(move func() bool) bool {
+	if !b.Practice {
+		return move()
+	}
+	s := b.Snapshot()
+	effective := b.Clicks.Effective()
+	ok := move()
+	if b.Clicks.Effective() != effective {
+		b.undo = append(b.undo, s)
+		b.redo = nil
+	}
+	return ok
+}
+
+// rewind goes back or forth a move, from one stack to the other.
+func (b *Board) rewind(from, to *[]engine.Snapshot) bool {
+	if len(*from) == 0 {
+		return false
+	}
+	*to = append(*to, b.Snapshot())
+	b.Rewind((*from)[len(*from)-1])
+	*from = (*from)[:len(*from)-1]
+	b.changed()
+	b.stopXray()
+	b.renderAll()
+	return true
+}
+
+func Undo() {
+	if GameBoard.Practice && GameBoard.rewind(&GameBoard.undo, &GameBoard.redo) {
+		rewound()
+	}
+}
+
+func Redo() {
+	if GameBoard.Practice && GameBoard.rewind(&GameBoard.redo, &GameBoard.undo) {
+		rewound()
+	}
+}
+
+// rewound shows the board after an undo or redo. Games can be undone out
+// of their end and redone into it.
+func rewound() {
+	Counter.Set(GameBoard.Mines - GameBoard.Flags)
+	UpdateTitle()
+	Game.Result = nil
+	if GameBoard.Over() {
+		st := GameBoard.Stats(Game.Elapsed(time.Now()))
+		Game.Result = &st
+	}
+}
+
+// TogglePractice switches practice mode for the games to come. Switching
+// it on also turns the game in progress into practice, for good.
+func TogglePractice() {
+	Settings.Practice = !Settings.Practice
+	if Settings.Practice || GameBoard.State == engine.GameReady {
+		GameBoard.Practice = Settings.Practice
+	}
+	UpdateTitle()
+}
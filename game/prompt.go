@@ -0,0 +1,171 @@
+package game
+
+import (
+	"errors"
+	"image/color"
+	"megamine/engine"
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Overlay is drawn on top of the board and takes every input while open.
+type Overlay interface {
+	// Update reports whether the overlay is still open.
+	Update() bool
+	Draw(s *ebiten.Image)
+}
+
+var overlayColor = color.RGBA{
+	R: 0x00,
+	G: 0x00,
+	B: 0x00,
+	A: 0xd0,
+}
+
+const (
+	charWidth  = 6
+	lineHeight = 16
+)
+
+// DrawPanel darkens the board area and prints lines of text over it.
+func DrawPanel(s *ebiten.Image, lines []string) {
+	b := GameBoard
+	vector.DrawFilledRect(s, float32(b.Pos.X), float32(b.Pos.Y),
+		float32(b.X*16), float32(b.Y*16), overlayColor, false)
+	for i, l := range lines {
+		ebitenutil.DebugPrintAt(s, l, b.Pos.X+4, b.Pos.Y+4+i*lineHeight)
+	}
+}
+
+// Message shows an error until Esc or Enter is pressed, for errors the
+// game can carry on after.
+type Message struct {
+	Text string
+}
+
+func (m *Message) Update() bool {
+	return !inpututil.IsKeyJustPressed(ebiten.KeyEscape) &&
+		!inpututil.IsKeyJustPressed(ebiten.KeyEnter)
+}
+
+func (m *Message) Draw(s *ebiten.Image) {
+	width := max(GameBoard.X*16/charWidth-1, 1)
+	var lines []string
+	for _, r := range []rune(m.Text) {
+		if len(lines) == 0 || len([]rune(lines[len(lines)-1])) >= width {
+			lines = append(lines, "")
+		}
+		lines[len(lines)-1] += string(r)
+	}
+	DrawPanel(s, lines)
+}
+
+type PromptField struct {
+	Label string
+	Text  []rune
+}
+
+// Prompt asks for one or more numbers. Tab and enter move to the next
+// field; enter on the last one accepts.
+type Prompt struct {
+	Fields []PromptField
+	Focus  int
+	Err    string
+	// Accept is called with the entered texts. The prompt stays open and
+	// shows the error if it returns one.
+	Accept func(texts []string) error
+}
+
+func NewPrompt(accept func(texts []string) error, labels ...string) *Prompt {
+	p := &Prompt{
+		Accept: accept,
+	}
+	for _, l := range labels {
+		p.Fields = append(p.Fields, PromptField{Label: l})
+	}
+	return p
+}
+
+func (p *Prompt) Update() bool {
+	f := &p.Fields[p.Focus]
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if (r >= '0' && r <= '9') || r == '-' {
+			f.Text = append(f.Text, r)
+		}
+	}
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		return false
+	case inpututil.IsKeyJustPressed(ebiten.KeyBackspace):
+		if len(f.Text) > 0 {
+			f.Text = f.Text[:len(f.Text)-1]
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		if p.Focus > 0 {
+			p.Focus--
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyTab),
+		inpututil.IsKeyJustPressed(ebiten.KeyDown),
+		inpututil.IsKeyJustPressed(ebiten.KeyEnter) && p.Focus < len(p.Fields)-1:
+		p.Focus = (p.Focus + 1) % len(p.Fields)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		texts := make([]string, len(p.Fields))
+		for i, f := range p.Fields {
+			texts[i] = string(f.Text)
+		}
+		if err := p.Accept(texts); err != nil {
+			p.Err = err.Error()
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+func (p *Prompt) Draw(s *ebiten.Image) {
+	var lines []string
+	for i, f := range p.Fields {
+		l := " " + f.Label + string(f.Text)
+		if i == p.Focus {
+			l = ">" + l[1:] + "_"
+		}
+		lines = append(lines, l)
+	}
+	if p.Err != "" {
+		lines = append(lines, p.Err)
+	}
+	DrawPanel(s, lines)
+}
+
+func NewSeedPrompt() *Prompt {
+	return NewPrompt(func(texts []string) error {
+		seed, err := strconv.ParseInt(texts[0], 10, 64)
+		if err != nil {
+			return errors.New("invalid seed")
+		}
+		return ResetGameSeed(seed)
+	}, "Seed: ")
+}
+
+func NewCustomPrompt() *Prompt {
+	p := NewPrompt(func(texts []string) error {
+		var n [3]int
+		for i, t := range texts {
+			v, err := strconv.Atoi(t)
+			if err != nil {
+				return errors.New("invalid number")
+			}
+			n[i] = v
+		}
+		return SetDifficulty(engine.DifficultyOf(n[0], n[1], n[2]))
+	}, "Width:  ", "Height: ", "Mines:  ")
+	d := Settings.Difficulty
+	for i, v := range []int{d.X, d.Y, d.Mines} {
+		p.Fields[i].Text = []rune(strconv.Itoa(v))
+	}
+	return p
+}
@@ -0,0 +1,88 @@
+package game
+
+import (
+	"fmt"
+	"megamine/replay"
+	"megamine/store"
+	"time"
+)
+
+// Recorder keeps every change of the cursor during the current game.
+type Recorder struct {
+	rep     *replay.Replay
+	startAt time.Time
+	last    replay.Event
+	done    bool
+}
+
+var Recording Recorder
+
+// LastReplay is the recording of the last finished game.
+var LastReplay *replay.Replay
+
+func buttons(ce *CursorEvent) int {
+	bt := 0
+	if ce.Left&KeyDown != 0 {
+		bt |= replay.ButtonLeft
+	}
+	if ce.Right&KeyDown != 0 {
+		bt |= replay.ButtonRight
+	}
+	if ce.Middle&KeyDown != 0 {
+		bt |= replay.ButtonMiddle
+	}
+	return bt
+}
+
+// Start begins recording the game on GameBoard.
+func (r *Recorder) Start() {
+	r.rep = replay.New(GameBoard.Board)
+	r.rep.Chord = replay.Chording(Input.Chord)
+	r.startAt = time.Now()
+	r.last = replay.Event{X: -1, Y: -1}
+	r.done = false
+}
+
+func (r *Recorder) Stop() {
+	r.rep = nil
+}
+
+// Shift leaves d out of the recording, such as the length of a pause.
+func (r *Recorder) Shift(d time.Duration) {
+	r.startAt = r.startAt.Add(d)
+}
+
+// Add records the cursor event. Undone moves cannot be played back, so
+// practice is not recorded.
+func (r *Recorder) Add(ce *CursorEvent, now time.Time) {
+	if r.rep == nil || r.done || GameBoard.Practice {
+		return
+	}
+	ev := replay.Event{
+		Time:    now.Sub(r.startAt),
+		X:       ce.X - GameBoard.Pos.X,
+		Y:       ce.Y - GameBoard.Pos.Y,
+		Buttons: buttons(ce),
+	}
+	if ev.X == r.last.X && ev.Y == r.last.Y && ev.Buttons == r.last.Buttons {
+		return
+	}
+	r.rep.Events = append(r.rep.Events, ev)
+	r.last = ev
+}
+
+// Finish stops recording and saves the replay of the finished game.
+func (r *Recorder) Finish() error {
+	if r.rep == nil || r.done || GameBoard.Practice {
+		return nil
+	}
+	r.done = true
+	r.rep.Layout = GameBoard.Layout()
+	LastReplay = r.rep
+	name := fmt.Sprintf("replays/%s-%d.json", time.Now().Format("20060102-150405"), r.rep.Seed)
+	p, err := store.Path(name)
+	if err != nil {
+		return err
+	}
+	return r.rep.Save(p)
+}
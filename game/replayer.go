@@ -0,0 +1,161 @@
+package game
+
+import (
+	"fmt"
+	"image/color"
+	"megamine/replay"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const replaySeekStep = 5 * time.Second
+
+var replaySpeeds = []float64{0.25, 0.5, 1, 2, 4, 8}
+
+// Replayer plays a recording back through the same path live input
+// takes. It is an overlay so nothing else reacts to input meanwhile.
+type Replayer struct {
+	rep    *replay.Replay
+	next   int
+	t      time.Duration
+	speed  int
+	paused bool
+	epoch  time.Time
+	last   replay.Event
+	// chord is the setting to go back to when the replay ends.
+	chord ChordSettings
+}
+
+func PlayReplay(rep *replay.Replay) error {
+	r := &Replayer{
+		rep:   rep,
+		speed: 2,
+		epoch: time.Now(),
+		chord: Input.Chord,
+	}
+	if err := r.restart(); err != nil {
+		return err
+	}
+	Input.Chord = ChordSettings(rep.Chord)
+	Recording.Stop()
+	Game.overlay = r
+	return nil
+}
+
+func (g *GameObject) Replaying() bool {
+	_, ok := g.overlay.(*Replayer)
+	return ok
+}
+
+func (r *Replayer) restart() error {
+	eb, err := r.rep.Board()
+	if err != nil {
+		return err
+	}
+	SetBoard(newBoard(eb))
+	Face.Clicked = false
+	r.next = 0
+	r.t = 0
+	r.last = replay.Event{X: -1, Y: -1}
+	return nil
+}
+
+func keyState(prev, cur, button int) KeyState {
+	switch {
+	case cur&button != 0 && prev&button == 0:
+		return KeyJust | KeyDown
+	case cur&button != 0:
+		return KeyDown
+	case prev&button != 0:
+		return KeyJust | KeyUp
+	default:
+		return KeyUp
+	}
+}
+
+func (r *Replayer) feed(ev replay.Event, at time.Duration) {
+	ce := &CursorEvent{
+		X:      GameBoard.Pos.X + ev.X,
+		Y:      GameBoard.Pos.Y + ev.Y,
+		Left:   keyState(r.last.Buttons, ev.Buttons, replay.ButtonLeft),
+		Middle: keyState(r.last.Buttons, ev.Buttons, replay.ButtonMiddle),
+		Right:  keyState(r.last.Buttons, ev.Buttons, replay.ButtonRight),
+	}
+	r.last = ev
+	Game.play(ce, r.epoch.Add(at))
+}
+
+// seek plays every event up to t, starting over if t is in the past.
+// It returns how many events were played.
+func (r *Replayer) seek(t time.Duration) int {
+	if t < r.t {
+		r.restart()
+	}
+	n := 0
+	for r.next < len(r.rep.Events) && r.rep.Events[r.next].Time <= t {
+		ev := r.rep.Events[r.next]
+		r.feed(ev, ev.Time)
+		r.next++
+		n++
+	}
+	r.t = t
+	return n
+}
+
+func (r *Replayer) Update() bool {
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		Input.Chord = r.chord
+		ResetGame()
+		return false
+	case inpututil.IsKeyJustPressed(ebiten.KeySpace):
+		r.paused = !r.paused
+	case inpututil.IsKeyJustPressed(ebiten.KeyHome):
+		r.seek(0)
+	case inpututil.IsKeyJustPressed(ebiten.KeyLeft):
+		r.seek(max(r.t-replaySeekStep, 0))
+	case inpututil.IsKeyJustPressed(ebiten.KeyRight):
+		r.seek(min(r.t+replaySeekStep, r.rep.Duration()))
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		r.speed = min(r.speed+1, len(replaySpeeds)-1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		r.speed = max(r.speed-1, 0)
+	}
+	if r.paused {
+		return true
+	}
+	if r.t >= r.rep.Duration() {
+		r.paused = true
+		return true
+	}
+	dt := time.Duration(float64(time.Second) / float64(ebiten.TPS()) * replaySpeeds[r.speed])
+	if r.seek(r.t+dt) == 0 {
+		// Live input is seen every frame, held buttons included.
+		r.feed(r.last, r.t)
+	}
+	return true
+}
+
+var replayBarColor = color.RGBA{
+	R: 0x00,
+	G: 0x00,
+	B: 0x00,
+	A: 0xa0,
+}
+
+func (r *Replayer) Draw(s *ebiten.Image) {
+	b := GameBoard
+	y := b.Pos.Y + b.Y*16 - lineHeight
+	vector.DrawFilledRect(s, float32(b.Pos.X), float32(y),
+		float32(b.X*16), lineHeight, replayBarColor, false)
+	state := ">"
+	if r.paused {
+		state = "||"
+	}
+	ebitenutil.DebugPrintAt(s, fmt.Sprintf("%s %gx %.1f/%.1fs", state,
+		replaySpeeds[r.speed], r.t.Seconds(), r.rep.Duration().Seconds()), b.Pos.X+2, y)
+}
@@ -0,0 +1,71 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"megamine/engine"
+	"megamine/store"
+	"time"
+)
+
+const (
+	saveName    = "save.json"
+	saveVersion = 1
+)
+
+type saveFile struct {
+	Version  int
+	Board    *engine.Board
+	Elapsed  time.Duration
+	Practice bool
+	Assisted bool
+	Settings GameSettings
+}
+
+// SaveGame writes the game in progress so it can be resumed later. A
+// game that is not in progress leaves no save behind.
+func SaveGame() error {
+	if Game.Replaying() {
+		return nil
+	}
+	if GameBoard.State != engine.GameActive {
+		return store.Remove(saveName)
+	}
+	return store.WriteJSON(saveName, &saveFile{
+		Version:  saveVersion,
+		Board:    GameBoard.Board,
+		Elapsed:  Game.Elapsed(time.Now()),
+		Practice: GameBoard.Practice,
+		Assisted: GameBoard.Assisted,
+		Settings: Settings,
+	})
+}
+
+// ResumeGame continues the saved game, if there is one.
+func ResumeGame() error {
+	var sf saveFile
+	if err := store.ReadJSON(saveName, &sf); err != nil {
+		return err
+	}
+	if sf.Version != saveVersion {
+		return errors.New(fmt.Sprintf("unsupported save version: %d", sf.Version))
+	}
+	if sf.Board == nil || sf.Board.State != engine.GameActive {
+		return errors.New("no game in progress in save")
+	}
+	if err := sf.Board.Restore(); err != nil {
+		return err
+	}
+	Settings.Difficulty = sf.Settings.Difficulty
+	Settings.Rules = sf.Settings.Rules
+	b := newBoard(sf.Board)
+	b.Practice = sf.Practice
+	b.Assisted = sf.Assisted
+	SetBoard(b)
+	// The start of a resumed game was not seen, so it is not recorded.
+	Recording.Stop()
+	Game.BeginAt = time.Now().Add(-sf.Elapsed)
+	SetClock(sf.Elapsed)
+	Counter.Set(GameBoard.Mines - GameBoard.Flags)
+	return nil
+}
@@ -0,0 +1,123 @@
+package game
+
+import (
+	"fmt"
+	"log"
+	"megamine/engine"
+	"megamine/store"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// HighScores is nil if the scores could not be read, so a broken file is
+// never overwritten.
+var HighScores *store.Scores
+
+func LoadHighScores() {
+	s, err := store.LoadScores()
+	if err != nil {
+		log.Print(err)
+		return
+	}
+	HighScores = s
+}
+
+// ranked reports whether the game on GameBoard counts towards the scores.
+// Boards played from a layout can be drilled, practice can be undone,
+// hints help and a no-guess board that was not found may need a guess, so
+// they do not.
+func ranked() bool {
+	return !GameBoard.Fixed && !GameBoard.Practice && !GameBoard.Assisted && !GameBoard.NoGuessFailed()
+}
+
+func category(b *engine.Board) string {
+	return store.Category(engine.DifficultyOf(b.X, b.Y, b.Mines).String(), b.Rules)
+}
+
+// recordScore counts the finished game and reports whether it set a best
+// time.
+func recordScore(st engine.Stats) bool {
+	if HighScores == nil || !ranked() {
+		return false
+	}
+	best := HighScores.Add(category(GameBoard.Board), store.Result{
+		Won:   st.Won,
+		Time:  st.Time,
+		BBBVs: st.BBBVPerSecond(),
+		At:    Game.EndAt,
+	})
+	if err := HighScores.Save(); err != nil {
+		log.Print(err)
+	}
+	return best
+}
+
+// ScoresView lists the records of every category played.
+type ScoresView struct {
+	lines []string
+	top   int
+}
+
+func NewScoresView() *ScoresView {
+	v := &ScoresView{}
+	v.lines = append(v.lines, "High scores (Esc)")
+	if HighScores == nil {
+		v.lines = append(v.lines, "unavailable")
+		return v
+	}
+	var names []string
+	for _, d := range engine.Presets {
+		names = append(names, d.String())
+	}
+	// Presets under other rules only show once there is a record, after
+	// the plain ones.
+	var variants, custom []string
+	for name := range HighScores.Categories {
+		_, err := engine.ParsePreset(store.CategoryDifficulty(name))
+		switch {
+		case err != nil:
+			custom = append(custom, name)
+		case name != store.CategoryDifficulty(name):
+			variants = append(variants, name)
+		}
+	}
+	sort.Strings(variants)
+	names = append(names, variants...)
+	sort.Strings(custom)
+	for _, name := range append(names, custom...) {
+		r := HighScores.Categories[name]
+		if r == nil {
+			v.lines = append(v.lines, name+" -")
+			continue
+		}
+		v.lines = append(v.lines,
+			fmt.Sprintf("%s %d/%d %.0f%%", name, r.Won, r.Played, r.WinRate()*100))
+		if r.Won > 0 {
+			v.lines = append(v.lines,
+				fmt.Sprintf(" time  %.3fs %s", r.BestTime.Seconds(), r.BestTimeAt.Format("01-02")),
+				fmt.Sprintf(" 3BV/s %.3f %s", r.Best3BVs, r.Best3BVsAt.Format("01-02")))
+		}
+		v.lines = append(v.lines, fmt.Sprintf(" streak %d, best %d", r.Streak, r.BestStreak))
+	}
+	return v
+}
+
+func (v *ScoresView) Update() bool {
+	rows := GameBoard.Y * 16 / lineHeight
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape), Input.JustPressed(ActionScores):
+		return false
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		v.top = max(v.top-1, 0)
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		v.top = max(min(v.top+1, len(v.lines)-rows), 0)
+	}
+	return true
+}
+
+func (v *ScoresView) Draw(s *ebiten.Image) {
+	rows := GameBoard.Y * 16 / lineHeight
+	DrawPanel(s, v.lines[v.top:min(v.top+rows, len(v.lines))])
+}
@@ -2,8 +2,11 @@ package game
 
 import (
 	"image"
+	"image/color"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
 const (
@@ -32,20 +35,48 @@ type segDigit struct {
 }
 
 type SegDisp struct {
-	disp  [3]segDigit
-	img   *ebiten.Image
-	value int
-	Pos   image.Point
+	disp []segDigit
+	// Decimals of the digits come after a point.
+	Decimals int
+	img      *ebiten.Image
+	value    int
+	Pos      image.Point
 }
 
 var Counter SegDisp
 var Clock SegDisp
 
+const (
+	pointWidth = 4
+	// segGap keeps the displays apart from the face.
+	segGap = 4
+)
+
+var segColor = color.RGBA{
+	R: 0xff,
+	A: 0xff,
+}
+
 func UpdatePosSegDisp() {
 	gw, _ := Game.Layout(ebiten.WindowSize())
 	Counter.Pos.Y, Clock.Pos.Y = Face.Pos.Y, Face.Pos.Y
 	Counter.Pos.X = Counter.Pos.Y
-	Clock.Pos.X = gw - Clock.Pos.Y - digitWidth*3
+	Clock.Pos.X = gw - Clock.Pos.Y - Clock.Width()
+}
+
+// headerWidth is the narrowest screen the displays and the face fit in.
+func headerWidth() int {
+	margin := (topHeight - FaceHeight) / 2
+	return 2*(margin+max(Counter.Width(), Clock.Width())+segGap) + FaceWidth
+}
+
+// SetClock shows d on the clock, as precisely as it has digits for.
+func SetClock(d time.Duration) {
+	unit := time.Second
+	for i := 0; i < Clock.Decimals; i++ {
+		unit /= 10
+	}
+	Clock.TrySet(int(d / unit))
 }
 
 // digit over 9 defaults to 9, negative value represents hyphen
@@ -98,57 +129,76 @@ func (sd *SegDisp) TrySet(v int) {
 	}
 	sd.Set(v)
 }
+
+// Set shows v with leading zeros, or as many nines as fit if it is too
+// large. Negative values get a hyphen in front.
 func (sd *SegDisp) Set(v int) {
 	defer sd.RenderAll()
 	sd.value = v
-	n1, n2, n3 := v/100, v/10, v%10
+	digits := sd.disp
 	if v < 0 {
 		sd.disp[0].SetHyphen()
-	} else if n1 > 9 {
-		sd.disp[0].Set(9)
-		sd.disp[1].Set(9)
-		sd.disp[2].Set(9)
-		return
-	} else {
-		sd.disp[0].Set(n1 % 10)
+		digits = sd.disp[1:]
+		v = -v
 	}
-	if n2 < -9 {
-		sd.disp[1].Set(9)
-		sd.disp[2].Set(9)
-		return
-	} else if n2 < 0 {
-		sd.disp[1].Set(-n2 % 10)
-	} else {
-		sd.disp[1].Set(n2 % 10)
+	limit := 1
+	for range digits {
+		limit *= 10
 	}
-	if n3 < 0 {
-		sd.disp[2].Set(-n3)
-	} else {
-		sd.disp[2].Set(n3)
+	v = min(v, limit-1)
+	for i := len(digits) - 1; i >= 0; i-- {
+		digits[i].Set(v % 10)
+		v /= 10
 	}
 }
 
+func (sd *SegDisp) Width() int {
+	return sd.img.Bounds().Dx()
+}
+
 func (sd *SegDisp) RenderAll() {
-	for i := 0; i < 3; i++ {
+	for i := range sd.disp {
 		sd.Render(i)
 	}
 }
 
 func (sd *SegDisp) Render(i int) {
-	op := ebiten.DrawImageOptions{}
-	switch i {
-	case 0:
-	case 1:
-		op.GeoM.Translate(digitWidth, 0)
-	case 2:
-		op.GeoM.Translate(digitWidth*2, 0)
-	default:
+	if i < 0 || i >= len(sd.disp) {
 		return
 	}
+	op := ebiten.DrawImageOptions{}
+	x := digitWidth * i
+	if point := len(sd.disp) - sd.Decimals; sd.Decimals > 0 && i >= point {
+		x += pointWidth
+		if i == point {
+			px := float32(digitWidth * point)
+			vector.DrawFilledRect(sd.img, px, 0, pointWidth, digitHeight, color.Black, false)
+			vector.DrawFilledRect(sd.img, px+1, digitHeight-4, 2, 2, segColor, false)
+		}
+	}
+	op.GeoM.Translate(float64(x), 0)
 	sd.img.DrawImage(sd.disp[i].img, &op)
 }
 
+// Init sizes the display for that many digits, the last decimals of them
+// after a point.
+func (sd *SegDisp) Init(digits, decimals int) {
+	sd.disp = make([]segDigit, digits)
+	sd.Decimals = decimals
+	w := digitWidth * digits
+	if decimals > 0 {
+		w += pointWidth
+	}
+	sd.img = ebiten.NewImage(w, digitHeight)
+	sd.Set(sd.value)
+}
+
 func InitSegDisp() {
-	Clock.img = ebiten.NewImage(digitWidth*3, digitHeight)
-	Counter.img = ebiten.NewImage(digitWidth*3, digitHeight)
+	t := Settings.Timer
+	digits := 3
+	if t.Extended {
+		digits = 4
+	}
+	Clock.Init(digits+t.Decimals, t.Decimals)
+	Counter.Init(3, 0)
 }
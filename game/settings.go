@@ -0,0 +1,52 @@
+package game
+
+import "megamine/engine"
+
+type GameSettings struct {
+	Difficulty engine.Difficulty
+	Rules      engine.Rules
+	Timer      TimerSettings
+	Practice   bool
+}
+
+type TimerSettings struct {
+	// Decimals is how many digits after the second the clock shows, up
+	// to 2.
+	Decimals int
+	// Extended clocks count past 999.
+	Extended bool
+}
+
+var Settings = GameSettings{
+	Difficulty: engine.Expert,
+	Rules:      engine.DefaultRules(),
+}
+
+// SetDifficulty starts a new game of the given size, keeping the old
+// one if the size is not playable.
+func SetDifficulty(d engine.Difficulty) error {
+	old := Settings.Difficulty
+	Settings.Difficulty = d
+	if err := ResetGameSeed(engine.NewSeed()); err != nil {
+		Settings.Difficulty = old
+		return err
+	}
+	return nil
+}
+
+func ToggleNoGuess() error {
+	Settings.Rules.NoGuess = !Settings.Rules.NoGuess
+	return ResetGame()
+}
+
+// ToggleNoFlag switches to games played without flags, or back.
+func ToggleNoFlag() error {
+	Settings.Rules.NoFlag = !Settings.Rules.NoFlag
+	return ResetGame()
+}
+
+// CycleFirstClick switches to the next first click policy.
+func CycleFirstClick() error {
+	Settings.Rules.FirstClick = (Settings.Rules.FirstClick + 1) % (engine.FirstClickZero + 1)
+	return ResetGame()
+}
@@ -0,0 +1,54 @@
+package game
+
+import (
+	"fmt"
+	"megamine/engine"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+func statsLines(st engine.Stats) []string {
+	head := fmt.Sprintf("Lost after %.3fs", st.Time.Seconds())
+	if st.Won {
+		head = fmt.Sprintf("Won in %.3fs", st.Time.Seconds())
+	}
+	lines := []string{
+		head,
+		fmt.Sprintf("3BV    %d/%d", st.Solved, st.BBBV),
+		fmt.Sprintf("3BV/s  %.3f", st.BBBVPerSecond()),
+		fmt.Sprintf("Clicks %d+%d+%d", st.Clicks.Left, st.Clicks.Right, st.Clicks.Chord),
+		fmt.Sprintf("IOE    %.3f", st.IOE()),
+		fmt.Sprintf("Corr.  %.3f", st.Correctness()),
+		fmt.Sprintf("RQP    %.3f", st.RQP()),
+	}
+	switch k := Input.KeyNames(ActionStats); {
+	case Game.NewBest:
+		lines = append(lines, "New best time!")
+	case k != "":
+		lines = append(lines, k+" hides")
+	}
+	return lines
+}
+
+// DrawStats shows how the finished game went in a box over the board.
+func DrawStats(s *ebiten.Image) {
+	if Game.Result == nil || Game.HideStats {
+		return
+	}
+	lines := statsLines(*Game.Result)
+	w := 0
+	for _, l := range lines {
+		w = max(w, len(l)*charWidth)
+	}
+	w += 8
+	h := len(lines)*lineHeight + 8
+	b := GameBoard
+	x := b.Pos.X + (b.X*16-w)/2
+	y := b.Pos.Y + (b.Y*16-h)/2
+	vector.DrawFilledRect(s, float32(x), float32(y), float32(w), float32(h), overlayColor, false)
+	for i, l := range lines {
+		ebitenutil.DebugPrintAt(s, l, x+4, y+4+i*lineHeight)
+	}
+}
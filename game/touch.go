@@ -0,0 +1,136 @@
+package game
+
+import (
+	"math"
+	"megamine/engine"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// dragDistance is how far a finger may move, in screen pixels, before a
+// touch is a drag instead of a tap.
+const dragDistance = 8
+
+// TouchInput turns touches into cursor events: a tap opens, a long press
+// flags and a tap on a number chords. One finger drags the zoomed board
+// around and two pinch to zoom.
+type TouchInput struct {
+	down      bool
+	id        ebiten.TouchID
+	start     time.Time
+	sx, sy    int
+	x, y      int
+	moved     bool
+	flagged   bool
+	pinching  bool
+	pinchDist float64
+	pinchZoom float64
+	// pinchX, pinchY is the canvas point that stays under the fingers.
+	pinchX, pinchY float64
+	// pending events are played in the next frames.
+	pending []*CursorEvent
+}
+
+var Touch TouchInput
+
+func (t *TouchInput) event(left, right KeyState) *CursorEvent {
+	x, y := toCanvas(t.x, t.y)
+	return &CursorEvent{X: x, Y: y, Left: left, Middle: KeyUp, Right: right}
+}
+
+// Event returns the cursor event of the touches this frame, or nil if
+// there are none to play.
+func (t *TouchInput) Event() *CursorEvent {
+	if len(t.pending) > 0 {
+		ce := t.pending[0]
+		t.pending = t.pending[1:]
+		return ce
+	}
+	ids := ebiten.AppendTouchIDs(nil)
+	switch {
+	case len(ids) >= 2:
+		t.pinch(ids[0], ids[1])
+		t.moved = true
+		return t.event(KeyUp, KeyUp)
+	case len(ids) == 1:
+		if t.pinching || !t.down || t.id != ids[0] {
+			// A finger left over from a pinch only drags.
+			t.moved = t.pinching
+			t.pinching = false
+			t.down = true
+			t.id = ids[0]
+			t.start = time.Now()
+			t.sx, t.sy = ebiten.TouchPosition(t.id)
+			t.x, t.y = t.sx, t.sy
+			t.flagged = false
+			if t.moved {
+				return t.event(KeyUp, KeyUp)
+			}
+			return t.event(KeyJust|KeyDown, KeyUp)
+		}
+		return t.held()
+	case t.down:
+		t.down = false
+		t.pinching = false
+		return t.released()
+	}
+	return nil
+}
+
+func (t *TouchInput) held() *CursorEvent {
+	x, y := ebiten.TouchPosition(t.id)
+	px, py := t.x, t.y
+	t.x, t.y = x, y
+	if !t.moved && math.Hypot(float64(x-t.sx), float64(y-t.sy)) > dragDistance {
+		t.moved = true
+	}
+	switch {
+	case t.moved:
+		Pan(Game.PanX+float64(x-px), Game.PanY+float64(y-py))
+		return t.event(KeyUp, KeyUp)
+	case t.flagged:
+		return t.event(KeyUp, KeyUp)
+	case time.Since(t.start) >= time.Duration(Input.Touch.LongPressMs)*time.Millisecond:
+		t.flagged = true
+		t.pending = append(t.pending, t.event(KeyUp, KeyJust|KeyUp))
+		return t.event(KeyUp, KeyJust|KeyDown)
+	}
+	return t.event(KeyDown, KeyUp)
+}
+
+// released ends a touch, with a tap if it was one. The button goes up
+// once more after, as the face acts on that.
+func (t *TouchInput) released() *CursorEvent {
+	up := t.event(KeyUp, KeyUp)
+	if t.moved || t.flagged {
+		return up
+	}
+	cx, cy := toCanvas(t.x, t.y)
+	if x, y, ok := GameBoard.cursorCell(&CursorEvent{X: cx, Y: cy}); ok {
+		c := GameBoard.Board.Board[y][x]
+		if c.State&engine.CellOpen != 0 && c.Nearby > 0 {
+			t.pending = append(t.pending, t.event(KeyJust|KeyUp, KeyJust|KeyUp), up)
+			return t.event(KeyDown, KeyDown)
+		}
+	}
+	t.pending = append(t.pending, up)
+	return t.event(KeyJust|KeyUp, KeyUp)
+}
+
+// pinch zooms by how far the two fingers are apart compared to when they
+// touched.
+func (t *TouchInput) pinch(a, b ebiten.TouchID) {
+	ax, ay := ebiten.TouchPosition(a)
+	bx, by := ebiten.TouchPosition(b)
+	dist := math.Hypot(float64(ax-bx), float64(ay-by))
+	mx, my := float64(ax+bx)/2, float64(ay+by)/2
+	if !t.pinching {
+		t.pinching = true
+		t.pinchDist = max(dist, 1)
+		t.pinchZoom = Game.Zoom
+		t.pinchX = (mx - Game.PanX) / Game.Zoom
+		t.pinchY = (my - Game.PanY) / Game.Zoom
+	}
+	SetZoom(t.pinchZoom*dist/t.pinchDist, t.pinchX, t.pinchY, mx, my)
+}
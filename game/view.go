@@ -0,0 +1,51 @@
+package game
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const maxZoom = 4
+
+// The screen can be zoomed into and panned around, for large boards on
+// small screens. Everything is drawn to a canvas of the unzoomed size,
+// which is shown scaled by Zoom and moved by PanX and PanY.
+
+// toCanvas turns a point of the screen into one of the canvas.
+func toCanvas(x, y int) (int, int) {
+	g := &Game
+	return int((float64(x) - g.PanX) / g.Zoom), int((float64(y) - g.PanY) / g.Zoom)
+}
+
+// SetZoom zooms to z, keeping the canvas point at cx, cy on the screen
+// at sx, sy.
+func SetZoom(z float64, cx, cy, sx, sy float64) {
+	g := &Game
+	g.Zoom = min(max(z, 1), maxZoom)
+	Pan(sx-cx*g.Zoom, sy-cy*g.Zoom)
+}
+
+// Pan moves the canvas to x, y on the screen, as far as it still covers
+// the screen.
+func Pan(x, y float64) {
+	g := &Game
+	w, h := float64(g.X), float64(g.Y)
+	g.PanX = min(max(x, w-w*g.Zoom), 0)
+	g.PanY = min(max(y, h-h*g.Zoom), 0)
+}
+
+// drawZoomed draws the canvas to the screen as zoomed and panned.
+func (g *GameObject) drawZoomed(screen *ebiten.Image, draw func(*ebiten.Image)) {
+	if g.Zoom == 1 {
+		draw(screen)
+		return
+	}
+	if g.canvas == nil || g.canvas.Bounds() != screen.Bounds() {
+		g.canvas = ebiten.NewImage(g.X, g.Y)
+	}
+	g.canvas.Clear()
+	draw(g.canvas)
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(g.Zoom, g.Zoom)
+	op.GeoM.Translate(g.PanX, g.PanY)
+	screen.DrawImage(g.canvas, op)
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"megamine/engine"
+	"megamine/store"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+type summary struct {
+	Difficulty  string
+	Games, Won  int
+	WinRate     float64
+	BestTime    float64
+	AverageTime float64
+	Best3BVs    float64
+	Average3BVs float64
+	AverageIOE  float64
+}
+
+func (s *summary) add(e store.Entry) {
+	s.Games++
+	s.AverageIOE += e.Stats.IOE()
+	if !e.Stats.Won {
+		return
+	}
+	s.Won++
+	t := e.Stats.Time.Seconds()
+	if s.BestTime == 0 || t < s.BestTime {
+		s.BestTime = t
+	}
+	s.AverageTime += t
+	s.Best3BVs = max(s.Best3BVs, e.Stats.BBBVPerSecond())
+	s.Average3BVs += e.Stats.BBBVPerSecond()
+}
+
+func (s *summary) finish() {
+	s.AverageIOE /= float64(s.Games)
+	s.WinRate = float64(s.Won) / float64(s.Games)
+	if s.Won > 0 {
+		s.AverageTime /= float64(s.Won)
+		s.Average3BVs /= float64(s.Won)
+	}
+}
+
+// summarize totals the games per category, presets first, and over all.
+// Games under other rules than the classic ones are totalled apart.
+func summarize(entries []store.Entry) []*summary {
+	byName := map[string]*summary{}
+	for _, e := range entries {
+		name := store.Category(e.Difficulty, e.Rules)
+		s := byName[name]
+		if s == nil {
+			s = &summary{Difficulty: name}
+			byName[name] = s
+		}
+		s.add(e)
+	}
+	var sums []*summary
+	for _, d := range engine.Presets {
+		if s := byName[d.Name]; s != nil {
+			sums = append(sums, s)
+			delete(byName, d.Name)
+		}
+	}
+	var variants []*summary
+	for name, s := range byName {
+		if _, err := engine.ParsePreset(store.CategoryDifficulty(name)); err == nil {
+			variants = append(variants, s)
+			delete(byName, name)
+		}
+	}
+	sort.Slice(variants, func(i, j int) bool { return variants[i].Difficulty < variants[j].Difficulty })
+	sums = append(sums, variants...)
+	var custom []*summary
+	for _, s := range byName {
+		custom = append(custom, s)
+	}
+	sort.Slice(custom, func(i, j int) bool { return custom[i].Difficulty < custom[j].Difficulty })
+	sums = append(sums, custom...)
+	all := &summary{Difficulty: "all"}
+	for _, e := range entries {
+		all.add(e)
+	}
+	if all.Games > 0 {
+		sums = append(sums, all)
+	}
+	for _, s := range sums {
+		s.finish()
+	}
+	return sums
+}
+
+type historyFilter struct {
+	from, to   time.Time
+	difficulty string
+	result     string
+	flags      string
+	// ranked leaves out games that do not count towards the scores.
+	ranked bool
+}
+
+func (f *historyFilter) match(e store.Entry) bool {
+	switch {
+	case !f.from.IsZero() && e.At.Before(f.from):
+		return false
+	case !f.to.IsZero() && !e.At.Before(f.to):
+		return false
+	case f.result == "won" && !e.Stats.Won, f.result == "lost" && e.Stats.Won:
+		return false
+	case f.flags == "nf" && !e.Rules.NoFlag, f.flags == "flagged" && e.Rules.NoFlag:
+		return false
+	case f.ranked && !e.Ranked:
+		return false
+	}
+	if f.difficulty == "custom" {
+		_, err := engine.ParsePreset(e.Difficulty)
+		return err != nil
+	}
+	return f.difficulty == "" || f.difficulty == e.Difficulty
+}
+
+func parseDay(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.ParseInLocation(dateLayout, s, time.Local)
+	if err != nil {
+		return time.Time{}, errors.New(fmt.Sprintf("invalid date: %s", s))
+	}
+	return t, nil
+}
+
+var gameHeader = []string{"at", "difficulty", "seed", "result", "time", "3bv", "solved",
+	"3bv/s", "left", "right", "chord", "ioe", "correctness", "first", "ng", "nf", "ranked"}
+
+func gameRow(e store.Entry) []string {
+	result := "lost"
+	if e.Stats.Won {
+		result = "won"
+	}
+	st := e.Stats
+	return []string{
+		e.At.Format(time.RFC3339),
+		e.Difficulty,
+		strconv.FormatInt(e.Seed, 10),
+		result,
+		fmt.Sprintf("%.3f", st.Time.Seconds()),
+		strconv.Itoa(st.BBBV),
+		strconv.Itoa(st.Solved),
+		fmt.Sprintf("%.3f", st.BBBVPerSecond()),
+		strconv.Itoa(st.Clicks.Left),
+		strconv.Itoa(st.Clicks.Right),
+		strconv.Itoa(st.Clicks.Chord),
+		fmt.Sprintf("%.3f", st.IOE()),
+		fmt.Sprintf("%.3f", st.Correctness()),
+		e.Rules.FirstClick.String(),
+		strconv.FormatBool(e.Rules.NoGuess),
+		strconv.FormatBool(e.Rules.NoFlag),
+		strconv.FormatBool(e.Ranked),
+	}
+}
+
+var summaryHeader = []string{"difficulty", "games", "won", "win rate", "best time",
+	"average time", "best 3bv/s", "average 3bv/s", "average ioe"}
+
+func summaryRow(s *summary) []string {
+	return []string{
+		s.Difficulty,
+		strconv.Itoa(s.Games),
+		strconv.Itoa(s.Won),
+		fmt.Sprintf("%.3f", s.WinRate),
+		fmt.Sprintf("%.3f", s.BestTime),
+		fmt.Sprintf("%.3f", s.AverageTime),
+		fmt.Sprintf("%.3f", s.Best3BVs),
+		fmt.Sprintf("%.3f", s.Average3BVs),
+		fmt.Sprintf("%.3f", s.AverageIOE),
+	}
+}
+
+func writeTable(w io.Writer, format string, header []string, rows [][]string) error {
+	switch format {
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(header)
+		cw.WriteAll(rows)
+		return cw.Error()
+	case "text":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, r := range append([][]string{header}, rows...) {
+			fmt.Fprintln(tw, strings.Join(r, "\t"))
+		}
+		return tw.Flush()
+	}
+	return errors.New(fmt.Sprintf("unknown format: %s", format))
+}
+
+// stats prints totals of the games in the history that pass the filters,
+// or the games themselves.
+func stats(args []string) error {
+	w := os.Stdout
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	from := fs.String("from", "", "first day to include, as "+dateLayout)
+	to := fs.String("to", "", "last day to include, as "+dateLayout)
+	difficulty := fs.String("difficulty", "", "beginner, intermediate, expert, custom or a size like 30x16/99")
+	result := fs.String("result", "", "won or lost")
+	flags := fs.String("flags", "", "nf for games played without flags, flagged for the others")
+	format := fs.String("format", "text", "text, csv or json")
+	games := fs.Bool("games", false, "list the games instead of totals")
+	ranked := fs.Bool("ranked", true, "leave out practice, assisted and layout games")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	f := historyFilter{difficulty: *difficulty, result: *result, flags: *flags, ranked: *ranked}
+	var err error
+	if f.from, err = parseDay(*from); err != nil {
+		return err
+	}
+	if f.to, err = parseDay(*to); err != nil {
+		return err
+	} else if !f.to.IsZero() {
+		f.to = f.to.AddDate(0, 0, 1)
+	}
+	if f.result != "" && f.result != "won" && f.result != "lost" {
+		return errors.New(fmt.Sprintf("unknown result: %s", f.result))
+	}
+	if f.flags != "" && f.flags != "nf" && f.flags != "flagged" {
+		return errors.New(fmt.Sprintf("unknown flags: %s", f.flags))
+	}
+
+	entries, err := store.ReadHistory()
+	if err != nil {
+		return err
+	}
+	var matched []store.Entry
+	for _, e := range entries {
+		if f.match(e) {
+			matched = append(matched, e)
+		}
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "\t")
+		if *games {
+			return enc.Encode(matched)
+		}
+		return enc.Encode(summarize(matched))
+	}
+	if *games {
+		rows := make([][]string, len(matched))
+		for i, e := range matched {
+			rows[i] = gameRow(e)
+		}
+		return writeTable(w, *format, gameHeader, rows)
+	}
+	var rows [][]string
+	for _, s := range summarize(matched) {
+		rows = append(rows, summaryRow(s))
+	}
+	return writeTable(w, *format, summaryHeader, rows)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"megamine/engine"
+	"megamine/store"
+	"testing"
+	"time"
+)
+
+func entry(difficulty string, at time.Time, won bool, seconds float64) store.Entry {
+	e := store.Entry{
+		At:         at,
+		Difficulty: difficulty,
+		Rules:      engine.DefaultRules(),
+		Ranked:     true,
+	}
+	e.Stats.Won = won
+	e.Stats.Time = time.Duration(seconds * float64(time.Second))
+	e.Stats.BBBV = 30
+	if won {
+		e.Stats.Solved = 30
+	}
+	return e
+}
+
+func TestHistoryFilter(t *testing.T) {
+	day := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+	nf := entry("expert", day, true, 100)
+	nf.Rules.NoFlag = true
+	practice := entry("expert", day, true, 100)
+	practice.Ranked = false
+	tests := []struct {
+		name string
+		f    historyFilter
+		e    store.Entry
+		want bool
+	}{
+		{"no filter", historyFilter{ranked: true}, entry("expert", day, false, 5), true},
+		{"from", historyFilter{from: day.AddDate(0, 0, 1)}, entry("expert", day, true, 5), false},
+		{"from same time", historyFilter{from: day}, entry("expert", day, true, 5), true},
+		{"to", historyFilter{to: day}, entry("expert", day, true, 5), false},
+		{"to later", historyFilter{to: day.Add(time.Second)}, entry("expert", day, true, 5), true},
+		{"difficulty", historyFilter{difficulty: "expert"}, entry("beginner", day, true, 5), false},
+		{"difficulty matched", historyFilter{difficulty: "beginner"}, entry("beginner", day, true, 5), true},
+		{"custom", historyFilter{difficulty: "custom"}, entry("20x20/60", day, true, 5), true},
+		{"custom preset", historyFilter{difficulty: "custom"}, entry("expert", day, true, 5), false},
+		{"size", historyFilter{difficulty: "20x20/60"}, entry("20x20/60", day, true, 5), true},
+		{"won", historyFilter{result: "won"}, entry("expert", day, false, 5), false},
+		{"won matched", historyFilter{result: "won"}, entry("expert", day, true, 5), true},
+		{"lost", historyFilter{result: "lost"}, entry("expert", day, true, 5), false},
+		{"nf", historyFilter{flags: "nf"}, entry("expert", day, true, 5), false},
+		{"nf matched", historyFilter{flags: "nf"}, nf, true},
+		{"flagged", historyFilter{flags: "flagged"}, nf, false},
+		{"ranked", historyFilter{ranked: true}, practice, false},
+		{"unranked", historyFilter{ranked: false}, practice, true},
+	}
+	for _, tt := range tests {
+		if got := tt.f.match(tt.e); got != tt.want {
+			t.Errorf("%s: match %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseDay(t *testing.T) {
+	d, err := parseDay("2024-03-10")
+	if err != nil || !d.Equal(time.Date(2024, 3, 10, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("parseDay = %v, %v", d, err)
+	}
+	if d, err := parseDay(""); err != nil || !d.IsZero() {
+		t.Errorf("parseDay of nothing = %v, %v", d, err)
+	}
+	if _, err := parseDay("10.03.2024"); err == nil {
+		t.Error("parsed a date of another layout")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	at := time.Date(2024, 3, 10, 12, 0, 0, 0, time.Local)
+	ng := entry("expert", at, true, 60)
+	ng.Rules.NoGuess = true
+	entries := []store.Entry{
+		entry("20x20/60", at, false, 10),
+		entry("expert", at, true, 100),
+		entry("beginner", at, true, 10),
+		entry("expert", at, false, 20),
+		ng,
+		entry("expert", at, true, 50),
+	}
+	want := []summary{
+		{Difficulty: "beginner", Games: 1, Won: 1, WinRate: 1, BestTime: 10, AverageTime: 10, Best3BVs: 3, Average3BVs: 3},
+		{Difficulty: "expert", Games: 3, Won: 2, WinRate: 2.0 / 3, BestTime: 50, AverageTime: 75, Best3BVs: 0.6, Average3BVs: 0.45},
+		{Difficulty: "expert ng", Games: 1, Won: 1, WinRate: 1, BestTime: 60, AverageTime: 60, Best3BVs: 0.5, Average3BVs: 0.5},
+		{Difficulty: "20x20/60", Games: 1},
+		{Difficulty: "all", Games: 6, Won: 4, WinRate: 4.0 / 6, BestTime: 10, AverageTime: 55, Best3BVs: 3, Average3BVs: 1.1},
+	}
+	got := summarize(entries)
+	if len(got) != len(want) {
+		t.Fatalf("%d totals, want %d", len(got), len(want))
+	}
+	near := func(a, b float64) bool { return a-b < 1e-9 && b-a < 1e-9 }
+	for i, w := range want {
+		g := got[i]
+		if g.Difficulty != w.Difficulty || g.Games != w.Games || g.Won != w.Won ||
+			!near(g.WinRate, w.WinRate) || !near(g.BestTime, w.BestTime) || !near(g.AverageTime, w.AverageTime) ||
+			!near(g.Best3BVs, w.Best3BVs) || !near(g.Average3BVs, w.Average3BVs) {
+			t.Errorf("total %d %+v\nwant %+v", i, *g, w)
+		}
+	}
+	if sums := summarize(nil); len(sums) != 0 {
+		t.Errorf("totals of no games: %+v", sums)
+	}
+}
@@ -0,0 +1,156 @@
+// Package layout reads and writes where the mines of a board are, in the
+// Minesweeper Board Format (.mbf) and as a text grid.
+package layout
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"megamine/engine"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Text grids have a row per line with these for mines and safe cells.
+const (
+	TextMine = '*'
+	TextSafe = '.'
+)
+
+// Load reads the board at path, as MBF if its extension is .mbf and as
+// text otherwise.
+func Load(path string) (*engine.Board, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(path, f)
+}
+
+// Read is Load for a board that is not a file of its own. The format is
+// taken from the extension of name.
+func Read(name string, r io.Reader) (*engine.Board, error) {
+	if IsMBF(name) {
+		return ReadMBF(r)
+	}
+	return ReadText(r)
+}
+
+// Save writes the board to path, as MBF if its extension is .mbf and as
+// text otherwise.
+func Save(path string, b *engine.Board) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if IsMBF(path) {
+		err = WriteMBF(f, b)
+	} else {
+		err = WriteText(f, b)
+	}
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func IsMBF(name string) bool {
+	return strings.ToLower(filepath.Ext(name)) == ".mbf"
+}
+
+// ReadMBF reads a width and height byte, a big-endian mine count and a
+// column and row byte for every mine.
+func ReadMBF(r io.Reader) (*engine.Board, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 4 {
+		return nil, errors.New("mbf is truncated")
+	}
+	x, y := int(data[0]), int(data[1])
+	n := int(data[2])<<8 | int(data[3])
+	if len(data) != 4+n*2 {
+		return nil, errors.New(fmt.Sprintf("mbf has %d bytes for %d mines", len(data)-4, n))
+	}
+	mines := make([]image.Point, n)
+	for i := range mines {
+		mines[i] = image.Point{int(data[4+i*2]), int(data[5+i*2])}
+	}
+	return engine.NewBoardLayout(x, y, mines)
+}
+
+func WriteMBF(w io.Writer, b *engine.Board) error {
+	if b.X > 255 || b.Y > 255 {
+		return errors.New("board too large for mbf")
+	}
+	mines := b.Layout()
+	if len(mines) > 0xffff {
+		return errors.New("too many mines for mbf")
+	}
+	data := []byte{byte(b.X), byte(b.Y), byte(len(mines) >> 8), byte(len(mines))}
+	for _, p := range mines {
+		data = append(data, byte(p.X), byte(p.Y))
+	}
+	_, err := w.Write(data)
+	return err
+}
+
+// ReadText reads a grid of TextMine and TextSafe. Blank lines and
+// surrounding spaces are ignored.
+func ReadText(r io.Reader) (*engine.Board, error) {
+	var rows []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		l := strings.TrimSpace(sc.Text())
+		if l == "" {
+			continue
+		}
+		if len(rows) > 0 && len(l) != len(rows[0]) {
+			return nil, errors.New(fmt.Sprintf("row %d is %d cells wide, not %d",
+				len(rows)+1, len(l), len(rows[0])))
+		}
+		rows = append(rows, l)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("empty board")
+	}
+	var mines []image.Point
+	for y, l := range rows {
+		for x, c := range []byte(l) {
+			switch c {
+			case TextMine:
+				mines = append(mines, image.Point{x, y})
+			case TextSafe:
+			default:
+				return nil, errors.New(fmt.Sprintf("unknown cell %q at %d,%d", c, x, y))
+			}
+		}
+	}
+	return engine.NewBoardLayout(len(rows[0]), len(rows), mines)
+}
+
+func WriteText(w io.Writer, b *engine.Board) error {
+	var buf bytes.Buffer
+	for y := 0; y < b.Y; y++ {
+		for x := 0; x < b.X; x++ {
+			if b.Board[y][x].State&engine.CellMine != 0 {
+				buf.WriteByte(TextMine)
+			} else {
+				buf.WriteByte(TextSafe)
+			}
+		}
+		buf.WriteByte('\n')
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,67 @@
+package layout
+
+import (
+	"bytes"
+	"image"
+	"megamine/engine"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	mines := []image.Point{{0, 0}, {29, 0}, {5, 7}, {0, 15}, {29, 15}}
+	b, err := engine.NewBoardLayout(30, 16, mines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"board.mbf", "board.txt"} {
+		t.Run(name, func(t *testing.T) {
+			p := filepath.Join(t.TempDir(), name)
+			if err := Save(p, b); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Load(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.X != b.X || got.Y != b.Y || !slices.Equal(got.Layout(), b.Layout()) {
+				t.Errorf("read %dx%d %v, want %dx%d %v", got.X, got.Y, got.Layout(), b.X, b.Y, b.Layout())
+			}
+		})
+	}
+}
+
+func TestReadText(t *testing.T) {
+	b, err := ReadText(strings.NewReader("\n  *..  \n\n...\n.*.\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []image.Point{{0, 0}, {1, 2}}
+	if b.X != 3 || b.Y != 3 || !slices.Equal(b.Layout(), want) {
+		t.Errorf("read %dx%d %v, want 3x3 %v", b.X, b.Y, b.Layout(), want)
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty.txt", ""},
+		{"ragged.txt", "...\n..\n"},
+		{"unknown.txt", "..x\n"},
+		{"full.txt", "**\n**\n"},
+		{"wide.txt", strings.Repeat(".", engine.MaxSize) + "*\n"},
+		{"short.mbf", "\x03\x03"},
+		{"truncated.mbf", "\x03\x03\x00\x02\x00\x00"},
+		{"off.mbf", "\x03\x03\x00\x01\x05\x00"},
+		{"twice.mbf", "\x03\x03\x00\x02\x01\x01\x01\x01"},
+	}
+	for _, tt := range tests {
+		if _, err := Read(tt.name, bytes.NewReader([]byte(tt.data))); err == nil {
+			t.Errorf("%s read without error", tt.name)
+		}
+	}
+}
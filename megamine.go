@@ -1,15 +1,154 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"log"
+	"megamine/engine"
 	"megamine/game"
+	"megamine/replay"
 	"os"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+var (
+	width      = flag.Int("w", 0, "board width (overrides the preset)")
+	height     = flag.Int("h", 0, "board height (overrides the preset)")
+	mines      = flag.Int("m", 0, "number of mines (overrides the preset)")
+	preset     = flag.String("preset", engine.Expert.Name, "beginner, intermediate or expert")
+	seed       = flag.Int64("seed", 0, "seed of the first board (random if unset)")
+	scale      = flag.Float64("scale", 1.5, "window scale")
+	decimals   = flag.Int("decimals", 0, "digits after the second on the clock, up to 2")
+	extended   = flag.Bool("extended", false, "let the clock count past 999")
+	first      = flag.String("first", engine.FirstClickXP.String(), "first click policy: xp, none, safe or zero")
+	noGuess    = flag.Bool("ng", false, "deal boards that need no guess")
+	ngBudget   = flag.Duration("ng-budget", engine.DefaultNoGuessBudget, "time to look for a board that needs no guess")
+	ngFallback = flag.String("ng-fallback", engine.FallbackRandom.String(), "board played when none is found in time: random or classic")
+	noFlag     = flag.Bool("nf", false, "play without flags")
+	boardPath  = flag.String("board", "", "play on the mines of a board file (.mbf or text)")
+	replayPath = flag.String("replay", "", "play back a recorded game (.json, .rmv or .avf)")
+	chordMid   = flag.Bool("chord-middle", true, "chord with the middle button (overrides input.json)")
+	chordLeft  = flag.Bool("chord-left", false, "chord with a left click on an opened number (overrides input.json)")
+	chordFlag  = flag.Bool("chord-flag", false, "right click a number to flag its neighbours when they must be mines (overrides input.json)")
+	resume     = flag.Bool("resume", true, "continue the saved game if there is one, unless a board or rule is given")
+)
+
+// fresh names the flags that pick a board or its rules. The saved game
+// is not resumed when one is given, since it would be played instead.
+var fresh = map[string]bool{
+	"w": true, "h": true, "m": true, "preset": true, "seed": true,
+	"first": true, "ng": true, "ng-budget": true, "ng-fallback": true, "nf": true, "board": true, "replay": true,
+}
+
+// settings applies the command line to the game settings and returns the
+// start options.
+func settings() (game.Options, error) {
+	d, err := engine.ParsePreset(*preset)
+	if err != nil {
+		return game.Options{}, err
+	}
+	if *width != 0 {
+		d.X = *width
+	}
+	if *height != 0 {
+		d.Y = *height
+	}
+	if *mines != 0 {
+		d.Mines = *mines
+	}
+	game.Settings.Difficulty = engine.DifficultyOf(d.X, d.Y, d.Mines)
+
+	fc, err := engine.ParseFirstClick(*first)
+	if err != nil {
+		return game.Options{}, err
+	}
+	game.Settings.Rules.FirstClick = fc
+	game.Settings.Rules.NoGuess = *noGuess
+	if *ngBudget <= 0 {
+		return game.Options{}, errors.New("ng-budget must be positive")
+	}
+	game.Settings.Rules.NoGuessBudget = *ngBudget
+	fb, err := engine.ParseFallback(*ngFallback)
+	if err != nil {
+		return game.Options{}, err
+	}
+	game.Settings.Rules.NoGuessFallback = fb
+	game.Settings.Rules.NoFlag = *noFlag
+
+	if *decimals < 0 || *decimals > 2 {
+		return game.Options{}, errors.New("decimals must be 0, 1 or 2")
+	}
+	game.Settings.Timer.Decimals = *decimals
+	game.Settings.Timer.Extended = *extended
+
+	if *scale <= 0 {
+		return game.Options{}, errors.New("scale must be positive")
+	}
+	opts := game.Options{
+		Seed:   engine.NewSeed(),
+		Scale:  *scale,
+		Resume: *resume,
+		Replay: *replayPath,
+		Board:  *boardPath,
+	}
+	resumeGiven := false
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "seed":
+			opts.Seed = *seed
+		case "chord-middle":
+			game.Input.Chord.Middle = *chordMid
+		case "chord-left":
+			game.Input.Chord.Left = *chordLeft
+		case "chord-flag":
+			game.Input.Chord.Flag = *chordFlag
+		}
+		if fresh[f.Name] {
+			opts.Resume = false
+		}
+		resumeGiven = resumeGiven || f.Name == "resume"
+	})
+	if resumeGiven {
+		opts.Resume = *resume
+	}
+	return opts, nil
+}
+
+// convert rewrites a replay in the format the extension of the output
+// names.
+func convert(args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: megamine convert <in> <out>")
+	}
+	rep, err := replay.Load(args[0])
+	if err != nil {
+		return err
+	}
+	return rep.Save(args[1])
+}
+
+// commands run instead of the game when named as the first argument.
+var commands = map[string]func(args []string) error{
+	"convert": convert,
+	"stats":   stats,
+	"bot":     bots,
+}
+
 func main() {
-	err := game.InitGame()
+	if len(os.Args) > 1 && commands[os.Args[1]] != nil {
+		if err := commands[os.Args[1]](os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	flag.Parse()
+	game.LoadInput()
+	opts, err := settings()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = game.InitGame(opts)
 	if err != nil {
 		log.Fatal(err)
 		os.Exit(1)
@@ -17,4 +156,4 @@ func main() {
 	if err := ebiten.RunGame(&game.Game); err != nil {
 		log.Fatal(err)
 	}
-}
\ No newline at end of file
+}
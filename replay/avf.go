@@ -0,0 +1,151 @@
+package replay
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"megamine/engine"
+	"time"
+)
+
+// AVF is the replay format of Minesweeper Arbiter. The board comes first,
+// then a text block in brackets with the timestamps, then a binary header
+// of varying length and then the mouse events, eight bytes each. The
+// header is not understood; the events are found by looking for the
+// first run of records that make sense.
+
+const (
+	avfBeginner     = 3
+	avfIntermediate = 4
+	avfExpert       = 5
+	avfCustom       = 6
+)
+
+// Bits of the first byte of an event. Every event moves the cursor.
+const (
+	avfMove       = 1 << 0
+	avfLeftDown   = 1 << 1
+	avfLeftUp     = 1 << 2
+	avfRightDown  = 1 << 3
+	avfRightUp    = 1 << 4
+	avfMiddleDown = 1 << 5
+	avfMiddleUp   = 1 << 6
+	// avfNoEffect is set on releases that did not click anything.
+	avfNoEffect = 1 << 7
+)
+
+const (
+	avfEventSize = 8
+	// avfRunLength events in a row must make sense to be taken as the
+	// start of the events.
+	avfRunLength = 3
+)
+
+type avfEvent struct {
+	kind int
+	t    time.Duration
+	x, y int
+}
+
+func decodeAVFEvent(b []byte) avfEvent {
+	return avfEvent{
+		kind: int(b[0]),
+		t: time.Duration(int(b[6])<<8|int(b[2])-1)*time.Second +
+			time.Duration(b[4])*10*time.Millisecond,
+		x: int(b[1])<<8 | int(b[3]),
+		y: int(b[5])<<8 | int(b[7]),
+	}
+}
+
+func (e avfEvent) valid(rep *Replay, after time.Duration) bool {
+	return e.kind&avfMove != 0 && e.t >= after &&
+		e.x < rep.X*16+16 && e.y < rep.Y*16+16
+}
+
+func ReadAVF(rd io.Reader) (*Replay, error) {
+	data, err := io.ReadAll(rd)
+	if err != nil {
+		return nil, err
+	}
+	r := &reader{data: data}
+	r.u8() // version
+	r.bytes(4)
+	rep := &Replay{
+		Version: Version,
+		Rules:   engine.DefaultRules(),
+	}
+	switch level := r.u8(); level {
+	case avfBeginner:
+		rep.X, rep.Y, rep.Mines = 8, 8, 10
+	case avfIntermediate:
+		rep.X, rep.Y, rep.Mines = 16, 16, 40
+	case avfExpert:
+		rep.X, rep.Y, rep.Mines = 30, 16, 99
+	case avfCustom:
+		rep.X, rep.Y, rep.Mines = r.u8()+1, r.u8()+1, r.u16()
+	default:
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, errors.New(fmt.Sprintf("unknown avf level: %d", level))
+	}
+	for i := 0; i < rep.Mines; i++ {
+		y, x := r.u8()-1, r.u8()-1
+		rep.Layout = append(rep.Layout, image.Point{x, y})
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	end := bytes.IndexByte(data[r.off:], ']')
+	if end < 0 {
+		return nil, errors.New("malformed avf header")
+	}
+	r.off += end + 1
+
+	start := -1
+	for off := r.off; off+avfEventSize*avfRunLength <= len(data); off++ {
+		ok := true
+		after := time.Duration(-time.Second)
+		for i := 0; i < avfRunLength && ok; i++ {
+			e := decodeAVFEvent(data[off+i*avfEventSize:])
+			ok = e.valid(rep, after)
+			after = e.t
+		}
+		if ok {
+			start = off
+			break
+		}
+	}
+	if start < 0 {
+		return nil, errors.New("no events in avf replay")
+	}
+
+	var hd held
+	after := time.Duration(-time.Second)
+	for off := start; off+avfEventSize <= len(data); off += avfEventSize {
+		e := decodeAVFEvent(data[off:])
+		if !e.valid(rep, after) {
+			break
+		}
+		after = e.t
+		pressed, released := 0, 0
+		for _, b := range []struct{ down, up, button int }{
+			{avfLeftDown, avfLeftUp, ButtonLeft},
+			{avfRightDown, avfRightUp, ButtonRight},
+			{avfMiddleDown, avfMiddleUp, ButtonMiddle},
+		} {
+			if e.kind&b.down != 0 {
+				pressed |= b.button
+			}
+			if e.kind&b.up != 0 {
+				released |= b.button
+			}
+		}
+		hd.add(e.t, e.x, e.y, pressed, released)
+	}
+	rep.Events = hd.events
+	return rep, nil
+}
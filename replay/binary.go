@@ -0,0 +1,62 @@
+package replay
+
+import "errors"
+
+var errTruncated = errors.New("replay is truncated")
+
+// reader reads the big-endian numbers of the binary formats. The first
+// error sticks and every later read returns zero.
+type reader struct {
+	data []byte
+	off  int
+	err  error
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.off+n > len(r.data) {
+		r.err = errTruncated
+		return nil
+	}
+	b := r.data[r.off : r.off+n]
+	r.off += n
+	return b
+}
+
+func (r *reader) uint(n int) int {
+	v := 0
+	for _, c := range r.bytes(n) {
+		v = v<<8 | int(c)
+	}
+	return v
+}
+
+func (r *reader) u8() int  { return r.uint(1) }
+func (r *reader) u16() int { return r.uint(2) }
+func (r *reader) u24() int { return r.uint(3) }
+func (r *reader) u32() int { return r.uint(4) }
+
+func (r *reader) left() int {
+	return len(r.data) - r.off
+}
+
+type writer struct {
+	data []byte
+}
+
+func (w *writer) uint(n, v int) {
+	for i := n - 1; i >= 0; i-- {
+		w.data = append(w.data, byte(v>>(8*i)))
+	}
+}
+
+func (w *writer) u8(v int)  { w.uint(1, v) }
+func (w *writer) u16(v int) { w.uint(2, v) }
+func (w *writer) u24(v int) { w.uint(3, v) }
+func (w *writer) u32(v int) { w.uint(4, v) }
+
+func (w *writer) bytes(b []byte) {
+	w.data = append(w.data, b...)
+}
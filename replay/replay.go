@@ -0,0 +1,166 @@
+// Package replay holds recorded games: the board they were played on and
+// every change of the cursor while playing.
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"megamine/engine"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const Version = 2
+
+const (
+	ButtonLeft = 1 << iota
+	ButtonRight
+	ButtonMiddle
+)
+
+// Event is the cursor after it moved or a button changed.
+type Event struct {
+	// Time is counted from when the board was dealt.
+	Time time.Duration
+	// X and Y are pixels from the top left corner of the board, with
+	// cells 16 pixels wide.
+	X, Y    int
+	Buttons int
+}
+
+type Replay struct {
+	Version int
+	X, Y    int
+	Mines   int
+	Seed    int64
+	Rules   engine.Rules
+	// Layout is where the mines ended up. Replays that have it are
+	// played on it instead of the board dealt from Seed.
+	Layout []image.Point
+	// Chord is how buttons chorded besides both at once, which the
+	// events mean nothing without.
+	Chord  Chording `json:",omitempty"`
+	Events []Event
+}
+
+type Chording struct {
+	Middle, Left, Flag bool
+}
+
+func New(b *engine.Board) *Replay {
+	return &Replay{
+		Version: Version,
+		X:       b.X,
+		Y:       b.Y,
+		Mines:   b.Mines,
+		Seed:    b.Seed,
+		Rules:   b.Rules,
+	}
+}
+
+// Board deals the board the replay was recorded on.
+func (r *Replay) Board() (*engine.Board, error) {
+	if r.Layout != nil {
+		b, err := engine.NewBoardLayout(r.X, r.Y, r.Layout)
+		if err != nil {
+			return nil, err
+		}
+		b.Rules = r.Rules
+		return b, nil
+	}
+	b, err := engine.NewBoard(r.X, r.Y, r.Mines, r.Seed)
+	if err != nil {
+		return nil, err
+	}
+	b.Rules = r.Rules
+	return b, nil
+}
+
+// held turns button transitions into events. Each call gives the
+// buttons pressed and released at t with the cursor at x, y.
+type held struct {
+	events  []Event
+	buttons int
+}
+
+func (h *held) add(t time.Duration, x, y, pressed, released int) {
+	h.buttons = (h.buttons | pressed) &^ released
+	h.events = append(h.events, Event{
+		Time:    max(t, 0),
+		X:       x,
+		Y:       y,
+		Buttons: h.buttons,
+	})
+}
+
+// Duration is the time of the last event.
+func (r *Replay) Duration() time.Duration {
+	if len(r.Events) == 0 {
+		return 0
+	}
+	return r.Events[len(r.Events)-1].Time
+}
+
+// Load reads a replay in the format its extension names: .rmv, .avf or
+// megamine's own.
+func Load(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(path, f)
+}
+
+// Read is Load for a replay that is not a file of its own. The format
+// is taken from the extension of name.
+func Read(name string, rd io.Reader) (*Replay, error) {
+	switch Format(name) {
+	case ".rmv":
+		return ReadRMV(rd)
+	case ".avf":
+		return ReadAVF(rd)
+	}
+	data, err := io.ReadAll(rd)
+	if err != nil {
+		return nil, err
+	}
+	r := &Replay{}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, err
+	}
+	if r.Version < 1 || r.Version > Version {
+		return nil, errors.New(fmt.Sprintf("unsupported replay version: %d", r.Version))
+	}
+	return r, nil
+}
+
+// Format returns the lower case extension of a replay file name.
+func Format(name string) string {
+	return strings.ToLower(filepath.Ext(name))
+}
+
+// Save writes the replay in the format the extension of path names: .rmv
+// or megamine's own. Nothing is written if the replay cannot be encoded.
+func (r *Replay) Save(path string) error {
+	var buf bytes.Buffer
+	var err error
+	switch Format(path) {
+	case ".rmv":
+		err = WriteRMV(&buf, r)
+	case ".avf":
+		err = errors.New("writing avf replays is not supported")
+	default:
+		err = json.NewEncoder(&buf).Encode(r)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
@@ -0,0 +1,188 @@
+package replay
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"io/fs"
+	"megamine/engine"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testReplay() *Replay {
+	rules := engine.DefaultRules()
+	rules.NoFlag = true
+	return &Replay{
+		Version: Version,
+		X:       9,
+		Y:       9,
+		Mines:   3,
+		Rules:   rules,
+		Layout:  []image.Point{{0, 0}, {8, 0}, {4, 8}},
+		Events: []Event{
+			{Time: 0, X: 10, Y: 20},
+			{Time: 120 * time.Millisecond, X: 10, Y: 20, Buttons: ButtonLeft},
+			{Time: 250 * time.Millisecond, X: 12, Y: 21},
+			{Time: time.Second, X: 40, Y: 40, Buttons: ButtonRight},
+			{Time: 1100 * time.Millisecond, X: 40, Y: 40, Buttons: ButtonRight | ButtonLeft},
+			// RMV has an event per button, so they are released one by one.
+			{Time: 1200 * time.Millisecond, X: 40, Y: 40, Buttons: ButtonRight},
+			{Time: 1300 * time.Millisecond, X: 40, Y: 40},
+			{Time: 2 * time.Second, X: 100, Y: 60, Buttons: ButtonMiddle},
+			{Time: 2100 * time.Millisecond, X: 100, Y: 60},
+		},
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, name := range []string{"game.json", "game.rmv"} {
+		t.Run(name, func(t *testing.T) {
+			want := testReplay()
+			p := filepath.Join(t.TempDir(), name)
+			if err := want.Save(p); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Load(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("read %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestSaveUnwritable(t *testing.T) {
+	noLayout := testReplay()
+	noLayout.Layout = nil
+	for name, r := range map[string]*Replay{"game.avf": testReplay(), "game.rmv": noLayout} {
+		p := filepath.Join(t.TempDir(), name)
+		if err := r.Save(p); err == nil {
+			t.Errorf("%s saved", name)
+		}
+		if _, err := os.Stat(p); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s left behind: %v", name, err)
+		}
+	}
+}
+
+// TestRealFiles reads the replays in testdata, saved by Viennasweeper and
+// Arbiter, against the readers' idea of the formats.
+func TestRealFiles(t *testing.T) {
+	var names []string
+	for _, ext := range []string{"*.rmv", "*.avf"} {
+		m, _ := filepath.Glob(filepath.Join("testdata", ext))
+		names = append(names, m...)
+	}
+	if len(names) == 0 {
+		t.Skip("no real replays in testdata")
+	}
+	for _, name := range names {
+		r, err := Load(name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if len(r.Layout) != r.Mines || len(r.Events) == 0 {
+			t.Errorf("%s: %d of %d mines, %d events", name, len(r.Layout), r.Mines, len(r.Events))
+		}
+		if _, err := r.Board(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		for i := 1; i < len(r.Events); i++ {
+			if r.Events[i].Time < r.Events[i-1].Time {
+				t.Errorf("%s: event %d goes back in time", name, i)
+				break
+			}
+		}
+	}
+}
+
+func TestRMVNeedsLayout(t *testing.T) {
+	r := testReplay()
+	r.Layout = nil
+	if err := WriteRMV(&bytes.Buffer{}, r); err == nil {
+		t.Error("wrote an rmv without mines")
+	}
+}
+
+// avf builds an AVF file of the layout ReadAVF expects: the board, a text
+// block and events after a header it skips.
+func avf(level int, mines []image.Point, events [][8]byte) []byte {
+	data := []byte{0, 0, 0, 0, 0, byte(level)}
+	for _, p := range mines {
+		data = append(data, byte(p.Y+1), byte(p.X+1))
+	}
+	data = append(data, []byte("[0|01.01.2020.00:00:00:0000|01.01.2020.00:00:10:0000|B0T0.00]")...)
+	data = append(data, 0, 0)
+	for _, e := range events {
+		data = append(data, e[:]...)
+	}
+	return data
+}
+
+// encodeAVFEvent encodes kind at t with the cursor at x, y.
+func encodeAVFEvent(kind int, t time.Duration, x, y int) [8]byte {
+	s := int(t/time.Second) + 1
+	cs := int(t % time.Second / (10 * time.Millisecond))
+	return [8]byte{byte(kind), byte(x >> 8), byte(s), byte(x), byte(cs), byte(y >> 8), byte(s >> 8), byte(y)}
+}
+
+func TestReadAVF(t *testing.T) {
+	var mines []image.Point
+	for i := 0; i < 10; i++ {
+		mines = append(mines, image.Point{i % 8, i / 8})
+	}
+	data := avf(avfBeginner, mines, [][8]byte{
+		encodeAVFEvent(avfMove, 0, 10, 20),
+		encodeAVFEvent(avfMove|avfLeftDown, 500*time.Millisecond, 10, 20),
+		encodeAVFEvent(avfMove|avfLeftUp, 600*time.Millisecond, 10, 20),
+		encodeAVFEvent(avfMove|avfRightDown, 1200*time.Millisecond, 30, 40),
+	})
+	r, err := Read("game.avf", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.X != 8 || r.Y != 8 || r.Mines != 10 || !reflect.DeepEqual(r.Layout, mines) {
+		t.Errorf("board %dx%d/%d %v, want 8x8/10 %v", r.X, r.Y, r.Mines, r.Layout, mines)
+	}
+	want := []Event{
+		{Time: 0, X: 10, Y: 20},
+		{Time: 500 * time.Millisecond, X: 10, Y: 20, Buttons: ButtonLeft},
+		{Time: 600 * time.Millisecond, X: 10, Y: 20},
+		{Time: 1200 * time.Millisecond, X: 30, Y: 40, Buttons: ButtonRight},
+	}
+	if !reflect.DeepEqual(r.Events, want) {
+		t.Errorf("events %+v\nwant %+v", r.Events, want)
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	var rmv bytes.Buffer
+	if err := WriteRMV(&rmv, testReplay()); err != nil {
+		t.Fatal(err)
+	}
+	var mines []image.Point
+	for i := 0; i < 10; i++ {
+		mines = append(mines, image.Point{i, 0})
+	}
+	files := map[string][]byte{
+		"game.rmv": rmv.Bytes(),
+		"game.avf": avf(avfBeginner, mines, nil),
+	}
+	for name, data := range files {
+		for _, n := range []int{0, 3, 10, len(data) / 2} {
+			if _, err := Read(name, bytes.NewReader(data[:n])); err == nil {
+				t.Errorf("%s cut to %d bytes read without error", name, n)
+			}
+		}
+	}
+	if _, err := Read("game.json", bytes.NewReader([]byte(`{"Version": 99}`))); err == nil {
+		t.Error("read a replay of a later version")
+	}
+}
@@ -0,0 +1,221 @@
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"megamine/engine"
+	"time"
+)
+
+// RMV is the replay format of Viennasweeper. A fixed header gives the size
+// of every section, so only the board, the options and the mouse events
+// are read and everything else is skipped. Signatures are neither checked
+// nor written, so exported files are not accepted by ranking sites.
+
+const (
+	rmvMagic = "*rmv"
+	rmvType  = 1
+	// The cursor is recorded in window pixels; this is where the board
+	// starts.
+	rmvBoardLeft = 12
+	rmvBoardTop  = 56
+)
+
+// Video events. 1 to 7 are mouse events, the ones between are board
+// changes and 15 to 17 end the game.
+const (
+	rmvTimestamp  = 0
+	rmvMove       = 1
+	rmvLeftDown   = 2
+	rmvLeftUp     = 3
+	rmvRightDown  = 4
+	rmvRightUp    = 5
+	rmvMiddleDown = 6
+	rmvMiddleUp   = 7
+	rmvGameEnd    = 15
+	rmvLastEvent  = 27
+)
+
+var rmvButtons = [...]struct{ pressed, released int }{
+	rmvMove:       {0, 0},
+	rmvLeftDown:   {ButtonLeft, 0},
+	rmvLeftUp:     {0, ButtonLeft},
+	rmvRightDown:  {ButtonRight, 0},
+	rmvRightUp:    {0, ButtonRight},
+	rmvMiddleDown: {ButtonMiddle, 0},
+	rmvMiddleUp:   {0, ButtonMiddle},
+}
+
+const rmvHeaderSize = 34
+
+type rmvHeader struct {
+	result, version, info, board, preflags, properties, video, checksum int
+}
+
+func ReadRMV(rd io.Reader) (*Replay, error) {
+	data, err := io.ReadAll(rd)
+	if err != nil {
+		return nil, err
+	}
+	r := &reader{data: data}
+	if string(r.bytes(4)) != rmvMagic {
+		return nil, errors.New("not an rmv replay")
+	}
+	if t := r.u16(); t != rmvType {
+		return nil, errors.New(fmt.Sprintf("unsupported rmv type: %d", t))
+	}
+	r.u32() // file size
+	h := rmvHeader{
+		result:     r.u16(),
+		version:    r.u16(),
+		info:       r.u32(),
+		board:      r.u32(),
+		preflags:   r.u16(),
+		properties: r.u32(),
+		video:      r.u32(),
+		checksum:   r.u16(),
+	}
+	r.bytes(h.result + h.version + h.info)
+	board := r.bytes(h.board)
+	r.bytes(h.preflags)
+	props := &reader{data: r.bytes(h.properties)}
+	video := &reader{data: r.bytes(h.video)}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	rep, err := readRMVBoard(board)
+	if err != nil {
+		return nil, err
+	}
+	props.u8() // question marks
+	rep.Rules.NoFlag = props.u8() == 1
+
+	var hd held
+	for video.left() > 0 {
+		c := video.u8()
+		switch {
+		case c == rmvTimestamp:
+			video.bytes(4)
+		case c <= rmvMiddleUp:
+			t := time.Duration(video.u24()) * time.Millisecond
+			x, y := video.u16()-rmvBoardLeft, video.u16()-rmvBoardTop
+			hd.add(t, x, y, rmvButtons[c].pressed, rmvButtons[c].released)
+		case c < rmvGameEnd || (c > rmvGameEnd+2 && c <= rmvLastEvent):
+			video.bytes(2)
+		case c <= rmvGameEnd+2:
+			video.bytes(video.left())
+		default:
+			return nil, errors.New(fmt.Sprintf("unknown rmv event: %d", c))
+		}
+	}
+	if video.err != nil {
+		return nil, video.err
+	}
+	rep.Events = hd.events
+	return rep, nil
+}
+
+// readRMVBoard reads the size and mines. Some writers put four more
+// bytes in front; the section size tells which.
+func readRMVBoard(data []byte) (*Replay, error) {
+	for _, skip := range []int{4, 0} {
+		r := &reader{data: data}
+		r.bytes(skip)
+		w, h, mines := r.u8(), r.u8(), r.u16()
+		if r.err != nil || r.left() != mines*2 {
+			continue
+		}
+		rep := &Replay{
+			Version: Version,
+			X:       w,
+			Y:       h,
+			Mines:   mines,
+			Rules:   engine.DefaultRules(),
+		}
+		for i := 0; i < mines; i++ {
+			x, y := r.u8(), r.u8()
+			rep.Layout = append(rep.Layout, image.Point{x, y})
+		}
+		return rep, nil
+	}
+	return nil, errors.New("malformed rmv board")
+}
+
+func WriteRMV(wr io.Writer, rep *Replay) error {
+	if rep.Layout == nil {
+		return errors.New("replay has no mine layout")
+	} else if rep.X > 255 || rep.Y > 255 {
+		return errors.New("board too large for rmv")
+	}
+	board := &writer{}
+	board.u32(0)
+	board.u8(rep.X)
+	board.u8(rep.Y)
+	board.u16(len(rep.Layout))
+	for _, p := range rep.Layout {
+		board.u8(p.X)
+		board.u8(p.Y)
+	}
+
+	props := &writer{}
+	props.u8(0) // question marks
+	nf := 0
+	if rep.Rules.NoFlag {
+		nf = 1
+	}
+	props.u8(nf)
+
+	video := &writer{}
+	prev := 0
+	for _, ev := range rep.Events {
+		codes := []int{}
+		changed := prev ^ ev.Buttons
+		for _, b := range []struct{ button, down, up int }{
+			{ButtonLeft, rmvLeftDown, rmvLeftUp},
+			{ButtonRight, rmvRightDown, rmvRightUp},
+			{ButtonMiddle, rmvMiddleDown, rmvMiddleUp},
+		} {
+			switch {
+			case changed&b.button == 0:
+			case ev.Buttons&b.button != 0:
+				codes = append(codes, b.down)
+			default:
+				codes = append(codes, b.up)
+			}
+		}
+		if len(codes) == 0 {
+			codes = append(codes, rmvMove)
+		}
+		for _, c := range codes {
+			video.u8(c)
+			video.u24(int(ev.Time / time.Millisecond))
+			video.u16(max(ev.X+rmvBoardLeft, 0))
+			video.u16(max(ev.Y+rmvBoardTop, 0))
+		}
+		prev = ev.Buttons
+	}
+	video.u8(rmvGameEnd)
+
+	version := []byte("megamine")
+	w := &writer{}
+	w.bytes([]byte(rmvMagic))
+	w.u16(rmvType)
+	w.u32(rmvHeaderSize + len(version) + len(board.data) + len(props.data) + len(video.data))
+	w.u16(0)
+	w.u16(len(version))
+	w.u32(0)
+	w.u32(len(board.data))
+	w.u16(0)
+	w.u32(len(props.data))
+	w.u32(len(video.data))
+	w.u16(0)
+	w.bytes(version)
+	w.bytes(board.data)
+	w.bytes(props.data)
+	w.bytes(video.data)
+	_, err := wr.Write(w.data)
+	return err
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"megamine/engine"
+	"os"
+	"time"
+)
+
+const historyName = "history.jsonl"
+
+// Entry is a finished game as kept in the history.
+type Entry struct {
+	At          time.Time
+	Seed        int64
+	X, Y, Mines int
+	Difficulty  string
+	Rules       engine.Rules
+	Stats       engine.Stats
+	// Ranked is unset for games that do not count towards the scores.
+	Ranked bool
+}
+
+// AppendHistory adds a game to the end of the history. Games are never
+// rewritten, so one line per game is enough.
+func AppendHistory(e Entry) error {
+	p, err := Path(historyName)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadHistory returns every game in the order they were played.
+func ReadHistory() ([]Entry, error) {
+	p, err := Path(historyName)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []Entry
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, errors.New(fmt.Sprintf("history line %d: %v", n, err))
+		}
+		entries = append(entries, e)
+	}
+	return entries, sc.Err()
+}
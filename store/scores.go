@@ -0,0 +1,121 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"megamine/engine"
+	"strings"
+	"time"
+)
+
+const (
+	scoresName    = "scores.json"
+	scoresVersion = 1
+)
+
+// Result is what a finished game counts towards the scores.
+type Result struct {
+	Won   bool
+	Time  time.Duration
+	BBBVs float64
+	At    time.Time
+}
+
+// Record is the standing in one category of games.
+type Record struct {
+	Played, Won        int
+	Streak, BestStreak int
+	// The bests are of won games and zero until one is won.
+	BestTime   time.Duration
+	BestTimeAt time.Time
+	Best3BVs   float64
+	Best3BVsAt time.Time
+}
+
+func (r *Record) WinRate() float64 {
+	if r.Played == 0 {
+		return 0
+	}
+	return float64(r.Won) / float64(r.Played)
+}
+
+// Scores keeps a record per category, such as a difficulty or a custom
+// size.
+type Scores struct {
+	Version    int
+	Categories map[string]*Record
+}
+
+// Category names the scores of a difficulty under rules. Boards that
+// need no guess, other first click policies and games without flags each
+// keep their own scores, as in "expert ng nf".
+func Category(difficulty string, rules engine.Rules) string {
+	c := difficulty
+	switch {
+	case rules.NoGuess:
+		c += " ng"
+	case rules.FirstClick != engine.FirstClickXP:
+		c += " " + rules.FirstClick.String()
+	}
+	if rules.NoFlag {
+		c += " nf"
+	}
+	return c
+}
+
+// CategoryDifficulty returns the difficulty a category is of.
+func CategoryDifficulty(category string) string {
+	d, _, _ := strings.Cut(category, " ")
+	return d
+}
+
+// LoadScores reads the scores, or starts empty ones if there are none yet.
+func LoadScores() (*Scores, error) {
+	s := &Scores{
+		Version:    scoresVersion,
+		Categories: map[string]*Record{},
+	}
+	err := ReadJSON(scoresName, s)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if s.Version != scoresVersion {
+		return nil, errors.New(fmt.Sprintf("unsupported scores version: %d", s.Version))
+	}
+	if s.Categories == nil {
+		s.Categories = map[string]*Record{}
+	}
+	return s, nil
+}
+
+func (s *Scores) Save() error {
+	return WriteJSON(scoresName, s)
+}
+
+// Add counts a finished game. It reports whether the game set a best time.
+func (s *Scores) Add(category string, res Result) bool {
+	r := s.Categories[category]
+	if r == nil {
+		r = &Record{}
+		s.Categories[category] = r
+	}
+	r.Played++
+	if !res.Won {
+		r.Streak = 0
+		return false
+	}
+	r.Won++
+	r.Streak++
+	r.BestStreak = max(r.BestStreak, r.Streak)
+	if res.BBBVs > r.Best3BVs {
+		r.Best3BVs, r.Best3BVsAt = res.BBBVs, res.At
+	}
+	if r.BestTime == 0 || res.Time < r.BestTime {
+		r.BestTime, r.BestTimeAt = res.Time, res.At
+		return true
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package store
+
+import (
+	"megamine/engine"
+	"testing"
+	"time"
+)
+
+func TestCategory(t *testing.T) {
+	rules := func(f func(r *engine.Rules)) engine.Rules {
+		r := engine.DefaultRules()
+		f(&r)
+		return r
+	}
+	tests := []struct {
+		rules engine.Rules
+		want  string
+	}{
+		{engine.DefaultRules(), "expert"},
+		{rules(func(r *engine.Rules) { r.NoFlag = true }), "expert nf"},
+		{rules(func(r *engine.Rules) { r.NoGuess = true }), "expert ng"},
+		{rules(func(r *engine.Rules) { r.NoGuess, r.NoFlag = true, true }), "expert ng nf"},
+		{rules(func(r *engine.Rules) { r.FirstClick = engine.FirstClickZero }), "expert " + engine.FirstClickZero.String()},
+		// No-guess boards are safe on the first click whatever the policy.
+		{rules(func(r *engine.Rules) { r.NoGuess, r.FirstClick = true, engine.FirstClickNone }), "expert ng"},
+	}
+	for _, tt := range tests {
+		got := Category("expert", tt.rules)
+		if got != tt.want {
+			t.Errorf("Category(%+v) = %q, want %q", tt.rules, got, tt.want)
+		}
+		if d := CategoryDifficulty(got); d != "expert" {
+			t.Errorf("CategoryDifficulty(%q) = %q", got, d)
+		}
+	}
+	if d := CategoryDifficulty(Category("30x20/130", engine.DefaultRules())); d != "30x20/130" {
+		t.Errorf("custom category is of %q", d)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	at := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &Scores{Categories: map[string]*Record{}}
+	games := []struct {
+		res  Result
+		best bool
+	}{
+		{Result{Won: false}, false},
+		{Result{Won: true, Time: 20 * time.Second, BBBVs: 1.5, At: at}, true},
+		{Result{Won: true, Time: 25 * time.Second, BBBVs: 2, At: at.Add(time.Hour)}, false},
+		{Result{Won: true, Time: 15 * time.Second, BBBVs: 1, At: at.Add(2 * time.Hour)}, true},
+		{Result{Won: false}, false},
+		{Result{Won: true, Time: 30 * time.Second, BBBVs: 1, At: at.Add(3 * time.Hour)}, false},
+	}
+	for i, g := range games {
+		if best := s.Add("expert", g.res); best != g.best {
+			t.Errorf("game %d best %v, want %v", i, best, g.best)
+		}
+	}
+	want := Record{
+		Played: 6, Won: 4,
+		Streak: 1, BestStreak: 3,
+		BestTime: 15 * time.Second, BestTimeAt: at.Add(2 * time.Hour),
+		Best3BVs: 2, Best3BVsAt: at.Add(time.Hour),
+	}
+	if r := *s.Categories["expert"]; r != want {
+		t.Errorf("record %+v\nwant %+v", r, want)
+	}
+	if r := s.Categories["expert"].WinRate(); r < 0.666 || r > 0.667 {
+		t.Errorf("win rate %v, want 2/3", r)
+	}
+	if len(s.Categories) != 1 {
+		t.Errorf("categories %v, want only expert", s.Categories)
+	}
+}
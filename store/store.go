@@ -0,0 +1,78 @@
+// Package store keeps megamine's files in the user's config directory.
+package store
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Dir returns the directory megamine keeps its files in, creating it if
+// needed.
+func Dir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(base, "megamine")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// Path returns where the named file is kept. Names may contain slashes;
+// the directories are created.
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return "", err
+	}
+	return p, nil
+}
+
+func ReadJSON(name string, v any) error {
+	p, err := Path(name)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// WriteJSON replaces the file atomically so a crash never leaves half of
+// it behind.
+func WriteJSON(name string, v any) error {
+	p, err := Path(name)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		return err
+	}
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p)
+}
+
+// Remove deletes the file. A file that does not exist is not an error.
+func Remove(name string) error {
+	p, err := Path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}